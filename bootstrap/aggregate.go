@@ -0,0 +1,95 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"api-gateway/internal/middleware"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/aggregate"
+
+	"github.com/gin-gonic/gin"
+)
+
+// aggregateRequest请求聚合路由的各个子请求，并把结果合并为一个JSON文档返回
+// 必需的子请求失败时返回502，可选的子请求失败时返回部分结果并设置X-Aggregate-Partial
+func (ga *GatewayApp) aggregateRequest(c *gin.Context, route *model.APIInfo) {
+	var params []string
+	if pattern, err := services.CompilePathPattern(route.Path); err == nil {
+		params = pattern.Params()
+	}
+	plan, err := aggregate.Compile(route.Aggregation, params)
+	if err != nil {
+		log.Printf("Error compiling aggregation for %s: %v", route.Name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid aggregation"})
+		return
+	}
+	var body []byte
+	if c.Request.Body != nil {
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+	}
+
+	result := plan.Run(c.Request.Context(), middleware.NewRequestVars(c), body, ga.sendCall(route))
+	if result.Failed != "" {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "Required call " + result.Failed + " failed: " + result.Errors[result.Failed],
+		})
+		return
+	}
+	if result.Partial() {
+		c.Header("X-Aggregate-Partial", "true")
+	}
+	c.JSON(http.StatusOK, result.Document)
+}
+
+// sendCall返回发送子请求的函数，子请求与普通转发一样签名并记录流量
+func (ga *GatewayApp) sendCall(route *model.APIInfo) aggregate.SendFunc {
+	return func(ctx context.Context, call *aggregate.Request) (*aggregate.Response, error) {
+		service, err := ga.downstreamService.GetByName(context.Background(), call.Downstream)
+		if err != nil || service.URL == "" {
+			return nil, errors.New("downstream not found")
+		}
+		backendURL, err := url.Parse(service.URL)
+		if err != nil {
+			return nil, errors.New("invalid downstream url")
+		}
+		tlsConfig, err := newUpstreamTLSConfig(service, ga.certStore)
+		if err != nil {
+			log.Printf("Error building upstream tls config: %v", err)
+			return nil, errors.New("invalid upstream tls config")
+		}
+		target, err := url.Parse(call.Path)
+		if err != nil {
+			return nil, errors.New("invalid path")
+		}
+		u := *backendURL
+		// 保留模板变量的转义，值中的/不会变成路径分隔符
+		u.Path = singleJoiningSlash(backendURL.Path, target.Path)
+		u.RawPath = singleJoiningSlash(backendURL.EscapedPath(), target.EscapedPath())
+		u.RawQuery = target.RawQuery
+
+		req, err := http.NewRequestWithContext(ctx, call.Method, u.String(), bytes.NewReader(call.Body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = call.Header
+		resp, err := ga.upstream(ga.clients.client(service, tlsConfig), service, route)(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &aggregate.Response{Status: resp.StatusCode, Header: resp.Header, Body: data}, nil
+	}
+}
@@ -0,0 +1,168 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"api-gateway/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultBatchConcurrency 路由未配置时批量请求同时执行的子请求数
+const defaultBatchConcurrency = 4
+
+// batchContextKey 标记批量请求中的子请求，子请求不能再发起批量请求
+type batchContextKey struct{}
+
+// batchItem 批量请求中的子请求
+type batchItem struct {
+	Method  string
+	Path    string            // 路径及查询参数，如/users/1?expand=orders
+	Headers map[string]string // 子请求的请求头，不会继承批量请求的请求头
+	Body    json.RawMessage   // 请求体，原样发送，未设置Content-Type时为application/json
+}
+
+// batchResult 子请求的响应，Body为JSON时原样嵌入，否则为字符串
+type batchResult struct {
+	Status  int
+	Headers map[string]string
+	Body    any
+}
+
+// batchRequest把批量请求中的每个子请求交给网关的路由处理，子请求同样经过认证、限流和流量统计
+// 响应数组与子请求的顺序一致
+func (ga *GatewayApp) batchRequest(c *gin.Context, route *model.APIInfo) {
+	if c.Request.Method != http.MethodPost {
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Batch requests must use POST"})
+		return
+	}
+	if c.Request.Context().Value(batchContextKey{}) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nested batch requests are not allowed"})
+		return
+	}
+	var items []batchItem
+	if err := c.ShouldBindJSON(&items); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(items) > route.BatchLimit {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Too many requests in batch"})
+		return
+	}
+	concurrency := route.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	ctx := context.WithValue(c.Request.Context(), batchContextKey{}, true)
+	results := make([]batchResult, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, item batchItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = ga.serveBatchItem(ctx, c.Request, item)
+		}(i, item)
+	}
+	wg.Wait()
+	c.JSON(http.StatusOK, results)
+}
+
+// serveBatchItem构造子请求并交给网关路由处理，子请求继承批量请求的客户端地址和TLS连接信息
+func (ga *GatewayApp) serveBatchItem(ctx context.Context, outer *http.Request, item batchItem) batchResult {
+	method := strings.ToUpper(item.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !strings.HasPrefix(item.Path, "/") || strings.HasPrefix(item.Path, "//") {
+		return batchError(http.StatusBadRequest, "path must be an absolute path")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, item.Path, bytes.NewReader(item.Body))
+	if err != nil {
+		return batchError(http.StatusBadRequest, "invalid request")
+	}
+	req.RequestURI = item.Path
+	req.Host = outer.Host
+	req.RemoteAddr = outer.RemoteAddr
+	req.TLS = outer.TLS
+	for name, value := range item.Headers {
+		req.Header.Set(name, value)
+	}
+	// 子请求的响应嵌入到JSON数组中，不能压缩，整个批量响应仍可以压缩
+	req.Header.Del("Accept-Encoding")
+	if len(item.Body) > 0 && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// 客户端地址相关的请求头使用批量请求的值，子请求不能伪造
+	for _, name := range []string{"X-Forwarded-For", "X-Real-Ip"} {
+		req.Header.Del(name)
+		if values := outer.Header.Values(name); len(values) > 0 {
+			req.Header[name] = values
+		}
+	}
+
+	recorder := newBatchRecorder()
+	ga.Router.ServeHTTP(recorder, req)
+	return recorder.result()
+}
+
+func batchError(status int, message string) batchResult {
+	return batchResult{Status: status, Body: gin.H{"error": message}}
+}
+
+// batchRecorder 保存子请求响应的ResponseWriter
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{header: make(http.Header)}
+}
+
+func (r *batchRecorder) Header() http.Header { return r.header }
+
+func (r *batchRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *batchRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+func (r *batchRecorder) Flush() {}
+
+// result返回子请求的响应，多个值的响应头以逗号合并
+func (r *batchRecorder) result() batchResult {
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	headers := make(map[string]string, len(r.header))
+	for name, values := range r.header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	var body any
+	if data := r.body.Bytes(); len(data) > 0 {
+		if json.Valid(data) {
+			body = json.RawMessage(data)
+		} else {
+			body = string(data)
+		}
+	}
+	return batchResult{Status: status, Headers: headers, Body: body}
+}
@@ -0,0 +1,103 @@
+package bootstrap
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-gateway/internal/model"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/transform"
+)
+
+// transformRequestBody按路由的规则转换JSON请求体，请求体不是合法的JSON时返回错误
+// 压缩的请求体解码后转换，转发给下游的是未压缩的内容
+func transformRequestBody(route *model.APIInfo, req *http.Request) error {
+	if len(route.RequestBody) == 0 || req.Body == nil || req.Body == http.NoBody || !isJSON(req.Header) {
+		return nil
+	}
+	rules, err := transform.CompileBodyRules(route.RequestBody)
+	if err != nil {
+		log.Printf("Error compiling request body rules for %s: %v", route.Name, err)
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if encoding := req.Header.Get("Content-Encoding"); encoding != "" {
+		if body, err = compress.Decode(encoding, body, CONFIG.Gateway.Compression.MaxDecodedSize); err != nil {
+			return err
+		}
+		req.Header.Del("Content-Encoding")
+	}
+	if body, err = rules.Apply(body); err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// transformResponse返回转换下游2xx的JSON响应体的upstreamFunc，转换后的响应再进入缓存
+// 响应体不是合法的JSON时原样返回
+func transformResponse(route *model.APIInfo, upstream upstreamFunc) upstreamFunc {
+	if route == nil || len(route.ResponseBody) == 0 {
+		return upstream
+	}
+	rules, err := transform.CompileBodyRules(route.ResponseBody)
+	if err != nil {
+		log.Printf("Error compiling response body rules for %s: %v", route.Name, err)
+		return upstream
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := upstream(req)
+		if err != nil || resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices ||
+			req.Method == http.MethodHead || !isJSON(resp.Header) {
+			return resp, err
+		}
+		if err := transformResponseBody(rules, resp); err != nil {
+			log.Printf("Error transforming response body for %s: %v", route.Name, err)
+		}
+		return resp, nil
+	}
+}
+
+// transformResponseBody转换响应体并修正相关的响应头，失败时响应保持不变
+func transformResponseBody(rules transform.BodyRules, resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	encoding := resp.Header.Get("Content-Encoding")
+	if encoding != "" {
+		if body, err = compress.Decode(encoding, body, CONFIG.Gateway.Compression.MaxDecodedSize); err != nil {
+			return err
+		}
+	}
+	transformed, err := rules.Apply(body)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(transformed))
+	resp.ContentLength = int64(len(transformed))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(transformed)))
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-MD5")
+	resp.Header.Del("Digest")
+	// 转换后的内容与下游的内容不再逐字节相同，强校验器改为弱校验器
+	if etag := resp.Header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		resp.Header.Set("ETag", "W/"+etag)
+	}
+	return nil
+}
+
+// isJSON判断内容类型是否为JSON
+func isJSON(header http.Header) bool {
+	return strings.Contains(strings.ToLower(header.Get("Content-Type")), "json")
+}
@@ -0,0 +1,299 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/internal/middleware"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/httpcache"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCacheEntrySize = 10 << 20         // 策略未配置时允许缓存的最大响应体
+	revalidateTimeout     = 30 * time.Second // 后台重新验证的超时时间
+)
+
+// 304响应中需要带上的缓存相关头部
+var notModifiedHeaders = []string{"Cache-Control", "Content-Location", "Date", "ETag", "Expires", "Last-Modified", "Vary"}
+
+// responseCache 路由的响应缓存
+type responseCache struct {
+	store   *httpcache.Store
+	service services.CachePolicyServiceImpl
+
+	mu           sync.Mutex
+	revalidating map[string]bool // 正在后台重新验证的缓存键，避免同一条目重复请求上游
+}
+
+func newResponseCache(db *pebble.DB, service services.CachePolicyServiceImpl, config CacheConfig) *responseCache {
+	rc := &responseCache{
+		store: httpcache.NewStore(db, httpcache.Options{
+			MemoryBytes:     config.MemoryMB << 20,
+			MemoryEntrySize: config.MemoryEntryKB << 10,
+		}),
+		service:      service,
+		revalidating: make(map[string]bool),
+	}
+	go rc.purgeLoop(10 * time.Minute)
+	return rc
+}
+
+// policy返回路由使用的缓存策略，未配置或加载失败时返回nil
+func (rc *responseCache) policy(route *model.APIInfo) *model.CachePolicy {
+	if route == nil || route.CachePolicy == "" {
+		return nil
+	}
+	policy, err := rc.service.GetByName(context.Background(), route.CachePolicy)
+	if err != nil {
+		log.Printf("Error loading cache policy %s: %v", route.CachePolicy, err)
+		return nil
+	}
+	return policy
+}
+
+// serve使用缓存响应请求，未命中时通过upstream请求下游服务并按需缓存
+func (rc *responseCache) serve(c *gin.Context, route *model.APIInfo, policy *model.CachePolicy, req *http.Request, upstream upstreamFunc) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		forwardResponse(c, req, upstream)
+		return
+	}
+	reqCC := httpcache.ParseCacheControl(c.Request.Header)
+	if reqCC.Has("no-store") {
+		c.Header("X-Cache", "BYPASS")
+		forwardResponse(c, req, upstream)
+		return
+	}
+
+	key := cacheKey(c, route, policy)
+	// 上游响应带Vary时，按Vary列出的请求头取值查找对应的变体
+	entry, err := rc.store.GetVariant(key, c.Request)
+	if err != nil {
+		log.Printf("Error reading cache entry: %v", err)
+	}
+	now := time.Now()
+	noCache := reqCC.Has("no-cache") || c.Request.Header.Get("Pragma") == "no-cache"
+	if entry != nil && !noCache {
+		if entry.Fresh(now) {
+			writeEntry(c, entry, "HIT")
+			return
+		}
+		if entry.StaleWithin(entry.StaleWhileRevalidate, now) {
+			rc.revalidate(key, route, policy, req, entry, upstream)
+			writeEntry(c, entry, "STALE")
+			return
+		}
+	}
+	// HEAD请求只使用已有的缓存，不用没有响应体的结果更新缓存
+	if c.Request.Method == http.MethodHead {
+		forwardResponse(c, req, upstream)
+		return
+	}
+
+	// 客户端的条件请求由缓存处理，发往上游的条件请求使用缓存条目的校验器
+	req.Header.Del("If-None-Match")
+	req.Header.Del("If-Modified-Since")
+	if entry != nil {
+		entry.Conditional(req)
+	}
+	resp, err := upstream(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		if entry != nil && entry.StaleWithin(entry.StaleIfError, now) {
+			writeEntry(c, entry, "STALE")
+			return
+		}
+		if err != nil {
+			writeForwardError(c, err)
+			return
+		}
+	}
+	if updated, status := rc.update(key, route, policy, c.Request, entry, resp); updated != nil {
+		writeEntry(c, updated, status)
+		return
+	}
+	c.Header("X-Cache", "MISS")
+	copyResponse(c, resp)
+}
+
+// update根据上游响应更新缓存，返回应当使用的条目及缓存状态，响应不可缓存时返回nil
+func (rc *responseCache) update(key string, route *model.APIInfo, policy *model.CachePolicy, clientReq *http.Request, old *httpcache.Entry, resp *http.Response) (*httpcache.Entry, string) {
+	now := time.Now()
+	if resp.StatusCode == http.StatusNotModified && old != nil {
+		entry := old.Revalidated(resp.Header, now)
+		ttl, _ := freshness(policy, entry.Header, now)
+		entry.FreshUntil = now.Add(ttl)
+		rc.set(key, policy, entry)
+		return entry, "REVALIDATED"
+	}
+
+	authorized := clientReq.Header.Get("Authorization") != "" && route.AuthMode == ""
+	if !httpcache.Storable(resp.StatusCode, resp.Header, authorized) {
+		return nil, ""
+	}
+	ttl, ok := freshness(policy, resp.Header, now)
+	if !ok {
+		return nil, ""
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ""
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	maxSize := policy.MaxEntrySize
+	if maxSize == 0 {
+		maxSize = defaultCacheEntrySize
+	}
+	if len(body) > maxSize {
+		return nil, ""
+	}
+	entry := httpcache.NewEntry(clientReq, resp.StatusCode, resp.Header, body, ttl, now)
+	if ttl == 0 && !entry.HasValidators() {
+		return nil, ""
+	}
+	rc.set(key, policy, entry)
+	return entry, "MISS"
+}
+
+// set应用策略覆盖的过期使用时间后保存条目
+func (rc *responseCache) set(key string, policy *model.CachePolicy, entry *httpcache.Entry) {
+	if policy.StaleWhileRevalidate > 0 {
+		entry.StaleWhileRevalidate = time.Duration(policy.StaleWhileRevalidate) * time.Second
+	}
+	if policy.StaleIfError > 0 {
+		entry.StaleIfError = time.Duration(policy.StaleIfError) * time.Second
+	}
+	if err := rc.store.SetVariant(key, entry); err != nil {
+		log.Printf("Error storing cache entry: %v", err)
+	}
+}
+
+// revalidate在后台向上游重新验证过期的条目，请求在处理函数返回前复制，不再依赖gin.Context
+func (rc *responseCache) revalidate(key string, route *model.APIInfo, policy *model.CachePolicy, req *http.Request, entry *httpcache.Entry, upstream upstreamFunc) {
+	// 同一缓存键的不同变体各自重新验证
+	variant := httpcache.VariantKey(key, entry.Vary)
+	rc.mu.Lock()
+	if rc.revalidating[variant] {
+		rc.mu.Unlock()
+		return
+	}
+	rc.revalidating[variant] = true
+	rc.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+	clientReq := req.Clone(ctx)
+	bg := req.Clone(ctx)
+	bg.Body = http.NoBody
+	bg.Header.Del("If-None-Match")
+	bg.Header.Del("If-Modified-Since")
+	entry.Conditional(bg)
+
+	go func() {
+		defer func() {
+			cancel()
+			rc.mu.Lock()
+			delete(rc.revalidating, variant)
+			rc.mu.Unlock()
+		}()
+		resp, err := upstream(bg)
+		if err != nil {
+			log.Printf("Error revalidating cache entry: %v", err)
+			return
+		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return
+		}
+		rc.update(key, route, policy, clientReq, entry, resp)
+	}()
+}
+
+// purgeLoop定期删除过期的缓存条目
+func (rc *responseCache) purgeLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := rc.store.Purge(); err != nil {
+			log.Printf("Error purging cache entries: %v", err)
+		}
+	}
+}
+
+// freshness返回条目的新鲜期，策略配置了强制缓存时间时忽略上游的声明
+func freshness(policy *model.CachePolicy, header http.Header, now time.Time) (time.Duration, bool) {
+	if policy.TTL > 0 {
+		return time.Duration(policy.TTL) * time.Second, true
+	}
+	return httpcache.Freshness(header, now)
+}
+
+// cacheKey根据路由、路径以及策略选择的查询参数和请求头生成缓存键
+func cacheKey(c *gin.Context, route *model.APIInfo, policy *model.CachePolicy) string {
+	var b strings.Builder
+	b.WriteString(route.Name)
+	b.WriteString("\n")
+	b.WriteString(c.Request.URL.Path)
+	b.WriteString("\n")
+	query := c.Request.URL.Query()
+	if len(policy.KeyQuery) > 0 {
+		selected := url.Values{}
+		for _, name := range policy.KeyQuery {
+			if values, ok := query[name]; ok {
+				selected[name] = values
+			}
+		}
+		query = selected
+	}
+	// Encode按参数名排序，参数顺序不同的请求使用同一个缓存键
+	b.WriteString(query.Encode())
+	for _, name := range policy.KeyHeaders {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString(":")
+		b.WriteString(strings.Join(c.Request.Header.Values(name), ","))
+	}
+	if policy.KeyConsumer {
+		b.WriteString("\nconsumer:")
+		b.WriteString(middleware.GetConsumer(c))
+	}
+	return b.String()
+}
+
+// writeEntry使用缓存条目响应请求，客户端的条件请求命中时返回304
+func writeEntry(c *gin.Context, entry *httpcache.Entry, status string) {
+	h := c.Writer.Header()
+	h.Set("Age", strconv.Itoa(int(entry.Age(time.Now()).Seconds())))
+	h.Set("X-Cache", status)
+	if entry.NotModified(c.Request) {
+		for _, name := range notModifiedHeaders {
+			for _, v := range entry.Header.Values(name) {
+				h.Add(name, v)
+			}
+		}
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	for name, values := range entry.Header {
+		for _, v := range values {
+			h.Add(name, v)
+		}
+	}
+	c.Writer.WriteHeader(entry.Status)
+	c.Writer.WriteHeaderNow()
+	if c.Request.Method != http.MethodHead {
+		if _, err := c.Writer.Write(entry.Body); err != nil {
+			log.Printf("Error writing cached response: %v", err)
+		}
+	}
+}
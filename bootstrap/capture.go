@@ -0,0 +1,113 @@
+package bootstrap
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/redact"
+)
+
+// captureRedactor 流量记录的脱敏器，按路由引用的脱敏策略编译并缓存
+type captureRedactor struct {
+	service  services.RedactionPolicyServiceImpl
+	global   redact.Rules
+	fallback *redact.Redactor // 全局规则，路由未配置策略或策略加载失败时使用
+
+	mu       sync.Mutex
+	compiled map[string]compiledRedactor
+}
+
+// compiledRedactor 编译后的脱敏器及策略更新时间，策略更新后重新编译
+type compiledRedactor struct {
+	updatedAt time.Time
+	redactor  *redact.Redactor
+}
+
+func newCaptureRedactor(service services.RedactionPolicyServiceImpl, global redact.Rules) (*captureRedactor, error) {
+	fallback, err := redact.Compile(redact.Defaults, global)
+	if err != nil {
+		return nil, err
+	}
+	return &captureRedactor{
+		service:  service,
+		global:   global,
+		fallback: fallback,
+		compiled: make(map[string]compiledRedactor),
+	}, nil
+}
+
+// redactor返回路由使用的脱敏器，策略无法加载时退回到全局规则，保证不会记录未脱敏的凭证
+func (cr *captureRedactor) redactor(route *model.APIInfo) *redact.Redactor {
+	if route == nil || route.RedactionPolicy == "" {
+		return cr.fallback
+	}
+	policy, err := cr.service.GetByName(context.Background(), route.RedactionPolicy)
+	if err != nil {
+		log.Printf("Error loading redaction policy %s: %v", route.RedactionPolicy, err)
+		return cr.fallback
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cached, ok := cr.compiled[policy.Name]; ok && cached.updatedAt.Equal(policy.UpdatedAt) {
+		return cached.redactor
+	}
+	rules := []redact.Rules{redact.Defaults}
+	if !policy.Override {
+		rules = append(rules, cr.global)
+	}
+	redactor, err := redact.Compile(append(rules, policy.Rules())...)
+	if err != nil {
+		log.Printf("Error compiling redaction policy %s: %v", route.RedactionPolicy, err)
+		return cr.fallback
+	}
+	cr.compiled[policy.Name] = compiledRedactor{updatedAt: policy.UpdatedAt, redactor: redactor}
+	return redactor
+}
+
+// redactRequestInfo返回脱敏后的请求信息副本
+func redactRequestInfo(r *redact.Redactor, info RequestInfo) RequestInfo {
+	return RequestInfo{
+		Method:  info.Method,
+		URL:     r.URL(info.URL),
+		Headers: r.Header(info.Headers),
+		Body:    r.Body(firstHeader(info.Headers, "Content-Type"), decodeCapturedBody(info.Headers, info.Body)),
+	}
+}
+
+// redactResponseInfo返回脱敏后的响应信息副本
+func redactResponseInfo(r *redact.Redactor, info ResponseInfo) ResponseInfo {
+	return ResponseInfo{
+		Method:     info.Method,
+		StatusCode: info.StatusCode,
+		Headers:    r.Header(info.Headers),
+		Body:       r.Body(firstHeader(info.Headers, "Content-Type"), decodeCapturedBody(info.Headers, info.Body)),
+	}
+}
+
+// decodeCapturedBody解码压缩的请求体或响应体，脱敏规则需要作用于解码后的内容
+// 无法解码时记录原始内容
+func decodeCapturedBody(h map[string][]string, body []byte) []byte {
+	encoding := firstHeader(h, "Content-Encoding")
+	if encoding == "" || len(body) == 0 {
+		return body
+	}
+	decoded, err := compress.Decode(encoding, body, CONFIG.Gateway.Compression.MaxDecodedSize)
+	if err != nil {
+		log.Printf("Error decoding captured body: %v", err)
+		return body
+	}
+	return decoded
+}
+
+func firstHeader(h map[string][]string, name string) string {
+	if values := h[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"api-gateway/internal/model"
+	"api-gateway/pkg/coalesce"
+)
+
+// 参与合并键的条件请求头，缓存发往上游的条件请求只有校验器相同时才能合并
+var coalesceConditionalHeaders = []string{"If-None-Match", "If-Modified-Since"}
+
+// upstreamResponse 共享给所有等待者的下游响应
+type upstreamResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// requestCoalescer 合并路由中相同的并发GET请求
+type requestCoalescer struct {
+	group coalesce.Group[*upstreamResponse]
+}
+
+// wrap为开启请求合并的路由包装upstream，同一消费者相同的并发请求只向下游发送一次
+func (rc *requestCoalescer) wrap(route *model.APIInfo, consumer string, upstream upstreamFunc) upstreamFunc {
+	if route == nil || route.CoalesceWait <= 0 {
+		return upstream
+	}
+	wait := time.Duration(route.CoalesceWait) * time.Millisecond
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || upstreamCredentials(route, req) {
+			return upstream(req)
+		}
+		shared, _, err := rc.group.Do(coalesceKey(route, consumer, req), wait, func() (*upstreamResponse, error) {
+			// 第一个请求的客户端断开时不能取消共享的下游请求
+			resp, err := upstream(req.WithContext(context.WithoutCancel(req.Context())))
+			if err != nil {
+				return nil, err
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			return &upstreamResponse{status: resp.StatusCode, header: resp.Header, body: body}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		// 每个等待者得到独立的响应，避免共用响应头和响应体
+		return &http.Response{
+			Status:        http.StatusText(shared.status),
+			StatusCode:    shared.status,
+			Header:        shared.header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(shared.body)),
+			ContentLength: int64(len(shared.body)),
+			Request:       req,
+		}, nil
+	}
+}
+
+// upstreamCredentials判断请求是否携带由下游校验的凭证，网关未认证时无法区分用户，这类请求不能合并
+func upstreamCredentials(route *model.APIInfo, req *http.Request) bool {
+	return route.AuthMode == "" && (req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "")
+}
+
+// coalesceKey根据路由、认证的消费者、请求方法、路径、查询参数和选择的请求头生成合并键，
+// 不同消费者的请求不会共享响应
+func coalesceKey(route *model.APIInfo, consumer string, req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(route.Name)
+	b.WriteString("\n")
+	b.WriteString(consumer)
+	b.WriteString("\n")
+	b.WriteString(req.Method)
+	b.WriteString(" ")
+	b.WriteString(req.URL.Path)
+	b.WriteString("\n")
+	b.WriteString(req.URL.Query().Encode())
+	for _, name := range slices.Concat(coalesceConditionalHeaders, route.CoalesceHeaders) {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString(":")
+		b.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return b.String()
+}
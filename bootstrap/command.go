@@ -0,0 +1,186 @@
+package bootstrap
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"api-gateway/internal/global"
+	"api-gateway/pkg/secret"
+
+	"github.com/cockroachdb/pebble"
+	"gorm.io/gorm"
+)
+
+// pebble中需要加密的流量记录前缀
+var capturePrefixes = []string{"request_", "response_"}
+
+// RunCommand执行命令行管理命令，执行前需要停止网关服务
+//
+//	keys rotate      生成新的主密钥并使用新密钥重新加密所有数据，仅适用于密钥文件
+//	keys reencrypt   使用当前主密钥重新加密所有数据，通过环境变量更换密钥后使用
+func RunCommand(args []string) error {
+	if len(args) != 2 || args[0] != "keys" {
+		return errors.New("usage: api-gateway keys rotate|reencrypt")
+	}
+
+	InitRuntime()
+	InitConfig()
+	InitLogger()
+	InitSecret()
+
+	// 先打开pebble，网关仍在运行时pebble已被锁定，避免只重新加密了一部分数据
+	db, err := pebble.Open(filepath.Join(DB_PATH, "pebble"), nil)
+	if err != nil {
+		return fmt.Errorf("open pebble (is the gateway still running?): %v", err)
+	}
+	defer db.Close()
+
+	switch args[1] {
+	case "rotate":
+		if os.Getenv(secret.EnvMasterKey) != "" {
+			return fmt.Errorf("master key is provided by %s, prepend a new key to it and run keys reencrypt", secret.EnvMasterKey)
+		}
+		key, err := secret.GenerateKey()
+		if err != nil {
+			return err
+		}
+		if err := secret.AddKeyToFile(masterKeyPath(), key); err != nil {
+			return err
+		}
+		InitSecret()
+		fmt.Printf("new master key %s added to %s\n", secret.KeyID(key), masterKeyPath())
+	case "reencrypt":
+	default:
+		return fmt.Errorf("unknown command keys %s", args[1])
+	}
+
+	InitDB()
+	fmt.Printf("re-encrypting data with master key %s\n", secret.ActiveKeyID())
+	if err := reencryptDB(global.DB); err != nil {
+		return err
+	}
+	if err := reencryptCertificates(); err != nil {
+		return err
+	}
+	return reencryptPebble(db)
+}
+
+// reencryptCertificates使用当前主密钥重新加密通过管理接口上传的证书私钥
+func reencryptCertificates() error {
+	count, err := newCertStore().ResealKeys()
+	if err != nil {
+		return fmt.Errorf("re-encrypt certificate keys: %v", err)
+	}
+	fmt.Printf("certificates: %d private keys re-encrypted\n", count)
+	return nil
+}
+
+// reencryptDB使用当前主密钥重新加密所有使用secret序列化器的字段，明文值会被加密
+func reencryptDB(db *gorm.DB) error {
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return err
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.TagSettings["SERIALIZER"] != "secret" {
+				continue
+			}
+			count, err := reencryptColumn(db, stmt.Schema.Table, field.DBName)
+			if err != nil {
+				return fmt.Errorf("re-encrypt %s.%s: %v", stmt.Schema.Table, field.DBName, err)
+			}
+			fmt.Printf("%s.%s: %d values re-encrypted\n", stmt.Schema.Table, field.DBName, count)
+		}
+	}
+	return nil
+}
+
+func reencryptColumn(db *gorm.DB, table, column string) (int, error) {
+	type row struct {
+		id    uint
+		value string
+	}
+	rows, err := db.Table(table).Select("id", column).Where(column + " <> ''").Rows()
+	if err != nil {
+		return 0, err
+	}
+	var values []row
+	for rows.Next() {
+		var r row
+		var value sql.NullString
+		if err := rows.Scan(&r.id, &value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		r.value = value.String
+		values = append(values, r)
+	}
+	rows.Close()
+
+	count := 0
+	for _, r := range values {
+		encrypted, changed, err := secret.Reencrypt(r.value)
+		if err != nil {
+			return count, fmt.Errorf("id %d: %v", r.id, err)
+		}
+		if !changed {
+			continue
+		}
+		if err := db.Table(table).Where("id = ?", r.id).UpdateColumn(column, encrypted).Error; err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// reencryptPebble使用当前主密钥重新加密pebble中的加密数据，未加密的流量记录会被加密
+func reencryptPebble(db *pebble.DB) error {
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		value := iter.Value()
+		if !secret.IsSealed(value) && !hasCapturePrefix(iter.Key()) {
+			continue
+		}
+		resealed, changed, err := secret.Reseal(value)
+		if err != nil {
+			iter.Close()
+			return fmt.Errorf("re-encrypt pebble key %q: %v", iter.Key(), err)
+		}
+		if !changed {
+			continue
+		}
+		if err := batch.Set(iter.Key(), resealed, nil); err != nil {
+			iter.Close()
+			return err
+		}
+		count++
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	fmt.Printf("pebble: %d values re-encrypted\n", count)
+	return nil
+}
+
+func hasCapturePrefix(key []byte) bool {
+	for _, prefix := range capturePrefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"api-gateway/internal/middleware"
+	"api-gateway/pkg/redact"
+)
+
+// Config 网关配置，保存在配置目录的config.json中
+type Config struct {
+	Gateway    GatewayConfig    `json:"gateway"`
+	Management ManagementConfig `json:"management"`
+}
+
+// GatewayConfig 网关转发服务配置
+type GatewayConfig struct {
+	Addr string           `json:"addr"` // HTTP监听地址
+	TLS  GatewayTLSConfig `json:"tls"`
+	// 可信代理的IP或CIDR，只有来自可信代理的X-Forwarded-For等请求头才会用于获取客户端IP
+	TrustedProxies []string `json:"trustedProxies"`
+	// 是否接受可信代理发送的PROXY协议头（v1/v2）
+	ProxyProtocol bool `json:"proxyProtocol"`
+	// 流量记录的全局脱敏规则，默认的凭证脱敏规则始终生效，无需配置
+	Redaction   redact.Rules      `json:"redaction"`
+	RateLimit   RateLimitConfig   `json:"rateLimit"`
+	Quota       QuotaConfig       `json:"quota"`
+	Cache       CacheConfig       `json:"cache"`
+	Compression CompressionConfig `json:"compression"`
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	Enabled   bool     `json:"enabled"`   // 是否压缩响应，默认关闭，升级后不会改变下游响应的编码
+	Encodings []string `json:"encodings"` // 支持的编码及优先顺序，可选br、zstd、gzip、deflate
+	MinSize   int      `json:"minSize"`   // 响应体达到该大小（字节）才压缩
+	Level     int      `json:"level"`     // 压缩级别，0为各编码的默认级别
+	// 压缩的内容类型，支持text/*和application/*+json这样的通配符
+	ContentTypes   []string `json:"contentTypes"`
+	MaxDecodedSize int64    `json:"maxDecodedSize"` // 请求体解压后的最大大小（字节）
+}
+
+// CacheConfig 响应缓存配置
+type CacheConfig struct {
+	MemoryMB      int `json:"memoryMb"`      // 内存缓存容量（MB）
+	MemoryEntryKB int `json:"memoryEntryKb"` // 超过该大小（KB）的条目只保存在pebble中
+}
+
+// QuotaConfig 配额配置
+type QuotaConfig struct {
+	Timezone string `json:"timezone"` // 自然日和自然月重置使用的时区，如Asia/Shanghai
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	MaxKeys int `json:"maxKeys"` // 本地内存最多保存的限流计数键数，超出时淘汰最久未使用的键
+	// 共享计数后端地址，如redis://:password@127.0.0.1:6379/0，为空时只使用本地限流
+	Backend        string `json:"backend"`
+	BatchSize      int    `json:"batchSize"`      // 本地累计多少个请求后与后端同步
+	SyncIntervalMs int    `json:"syncIntervalMs"` // 与后端同步的最长间隔（毫秒）
+	TimeoutMs      int    `json:"timeoutMs"`      // 后端请求超时（毫秒）
+}
+
+// GatewayTLSConfig 网关HTTPS监听配置
+type GatewayTLSConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Addr         string   `json:"addr"`         // HTTPS监听地址
+	MinVersion   string   `json:"minVersion"`   // 最低TLS版本
+	Certificates []string `json:"certificates"` // 使用的证书名称，为空时使用所有带私钥的证书
+	RedirectHTTP bool     `json:"redirectHttp"` // HTTP监听是否重定向到HTTPS
+	// 校验客户端证书的CA证书名称，客户端提供证书时必须由该CA签发，未提供证书时仍可访问
+	ClientCA string `json:"clientCa"`
+	// 未配置ClientCA时是否请求客户端证书（不校验），由路由配置的CA进行校验
+	RequestClientCert bool `json:"requestClientCert"`
+}
+
+// ManagementConfig 管理服务配置
+type ManagementConfig struct {
+	Addr              string      `json:"addr"`              // 管理服务监听地址
+	CertExpiryWarning int         `json:"certExpiryWarning"` // 证书到期预警天数
+	TrustedProxies    []string    `json:"trustedProxies"`    // 可信代理的IP或CIDR
+	Audit             AuditConfig `json:"audit"`
+}
+
+// AuditConfig 管理操作审计配置
+type AuditConfig struct {
+	RetentionDays int    `json:"retentionDays"` // 审计记录保留天数，0表示永久保留
+	ActorHeader   string `json:"actorHeader"`   // 标识操作人的请求头，未提供时使用Basic认证的用户名
+}
+
+// defaultConfig返回默认配置
+func defaultConfig() *Config {
+	return &Config{
+		Gateway: GatewayConfig{
+			Addr:           ":8080",
+			TrustedProxies: []string{},
+			TLS: GatewayTLSConfig{
+				Addr:       ":8443",
+				MinVersion: "1.2",
+			},
+			RateLimit: RateLimitConfig{
+				MaxKeys:        100000,
+				BatchSize:      10,
+				SyncIntervalMs: 100,
+				TimeoutMs:      200,
+			},
+			Quota: QuotaConfig{
+				Timezone: "UTC",
+			},
+			Cache: CacheConfig{
+				MemoryMB:      64,
+				MemoryEntryKB: 64,
+			},
+			Compression: CompressionConfig{
+				Enabled:   false,
+				Encodings: []string{"br", "zstd", "gzip"},
+				MinSize:   1024,
+				ContentTypes: []string{
+					"text/*",
+					"application/json",
+					"application/*+json",
+					"application/javascript",
+					"application/xml",
+					"application/*+xml",
+					"image/svg+xml",
+				},
+				MaxDecodedSize: 10 << 20,
+			},
+		},
+		Management: ManagementConfig{
+			Addr:              ":8081",
+			CertExpiryWarning: 30,
+			TrustedProxies:    []string{},
+			Audit: AuditConfig{
+				RetentionDays: 90,
+				ActorHeader:   "X-Actor",
+			},
+		},
+	}
+}
+
+func InitConfig() {
+	path := filepath.Join(CONFIG_PATH, "config.json")
+	config, err := loadConfig(path)
+	if err != nil {
+		panic(fmt.Sprintf("加载配置文件失败: %v", err))
+	}
+	CONFIG = config
+}
+
+// loadConfig加载配置文件，配置文件不存在时写入默认配置
+func loadConfig(path string) (*Config, error) {
+	config := defaultConfig()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return config, os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// quotaLocation返回配额重置使用的时区
+func quotaLocation() *time.Location {
+	loc, err := time.LoadLocation(CONFIG.Gateway.Quota.Timezone)
+	if err != nil {
+		panic(fmt.Sprintf("加载配额时区失败: %v", err))
+	}
+	return loc
+}
+
+// compressionOptions转换为压缩中间件的配置
+func compressionOptions(config CompressionConfig) middleware.CompressionOptions {
+	return middleware.CompressionOptions{
+		Enabled:        config.Enabled,
+		Encodings:      config.Encodings,
+		MinSize:        config.MinSize,
+		Level:          config.Level,
+		ContentTypes:   config.ContentTypes,
+		MaxDecodedSize: config.MaxDecodedSize,
+	}
+}
@@ -9,4 +9,6 @@ var (
 	DB_PATH string
 	// 配置文件目录
 	CONFIG_PATH string
+	// 配置信息
+	CONFIG *Config
 )
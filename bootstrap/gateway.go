@@ -3,18 +3,29 @@ package bootstrap
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"api-gateway/internal/api"
+	"api-gateway/internal/middleware"
+	"api-gateway/internal/model"
 	"api-gateway/internal/services"
+	"api-gateway/pkg/certstore"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/ipacl"
+	"api-gateway/pkg/proxyproto"
+	"api-gateway/pkg/secret"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/gin-gonic/gin"
@@ -25,7 +36,23 @@ type GatewayApp struct {
 	Router            *gin.Engine
 	API               *api.APIController
 	PebbleDB          *pebble.DB
+	apiService        services.APIServiceImpl
 	downstreamService services.DownstreamServiceImpl
+	consumerService   services.ConsumerServiceImpl
+	revocationService services.RevokedCertificateServiceImpl
+	ipAccessService   services.IPAccessListServiceImpl
+	corsService       services.CORSPolicyServiceImpl
+	jwtIssuerService  services.JWTIssuerServiceImpl
+	threatService     services.ThreatPolicyServiceImpl
+	wafPolicyService  services.WAFPolicyServiceImpl
+	wafRuleService    services.WAFRuleServiceImpl
+	certStore         *certstore.Store
+	redactor          *captureRedactor
+	rateLimitService  services.RateLimitPolicyServiceImpl
+	cache             *responseCache
+	soap              *soapBridges
+	coalescer         requestCoalescer
+	clients           *proxyClients
 }
 
 // NewGatewayApp创建并初始化用于网关转发的应用实例
@@ -43,35 +70,97 @@ func (ga *GatewayApp) Initialize() {
 		return
 	}
 
+	ga.apiService = services.NewAPIService()
 	ga.downstreamService = services.NewDownstreamService()
+	ga.consumerService = services.NewConsumerService()
+	ga.revocationService = services.NewRevokedCertificateService()
+	ga.ipAccessService = services.NewIPAccessListService()
+	ga.corsService = services.NewCORSPolicyService()
+	ga.jwtIssuerService = services.NewJWTIssuerService()
+	ga.threatService = services.NewThreatPolicyService()
+	ga.wafPolicyService = services.NewWAFPolicyService()
+	ga.wafRuleService = services.NewWAFRuleService()
+	ga.rateLimitService = services.NewRateLimitPolicyService()
+	ga.certStore = newCertStore()
+	ga.soap = newSOAPBridges(services.NewSOAPBridgeService())
+	ga.clients = newProxyClients()
+	ga.cache = newResponseCache(ga.PebbleDB, services.NewCachePolicyService(), CONFIG.Gateway.Cache)
+	rateLimitStore, err := newRateLimitStore(CONFIG.Gateway.RateLimit)
+	if err != nil {
+		fmt.Printf("Error creating rate limit store: %v", err)
+		return
+	}
+	ga.redactor, err = newCaptureRedactor(services.NewRedactionPolicyService(), CONFIG.Gateway.Redaction)
+	if err != nil {
+		fmt.Printf("Error compiling redaction rules: %v", err)
+		return
+	}
+	for _, encoding := range CONFIG.Gateway.Compression.Encodings {
+		if !compress.Supported(encoding) {
+			fmt.Printf("Error unsupported compression encoding: %s", encoding)
+			return
+		}
+	}
 	ga.Router = gin.Default()
+	err = ga.Router.SetTrustedProxies(CONFIG.Gateway.TrustedProxies)
+	if err != nil {
+		fmt.Printf("Error setting trusted proxies: %v", err)
+		return
+	}
 	// ga.Router.Use(middleware.NewMiddleware().Wrap)
+	ga.Router.Use(
+		middleware.NewRouteMiddleware(ga.apiService).ResolveRoute(),
+		middleware.NewIPAccessMiddleware(ga.ipAccessService).IPAccess(),
+		middleware.NewCORSMiddleware(ga.corsService).CORS(),
+		middleware.NewThreatProtectionMiddleware(ga.threatService).ThreatProtection(),
+		middleware.NewWAFMiddleware(ga.wafPolicyService, ga.wafRuleService).WAF(),
+		middleware.NewClientCertAuthMiddleware(ga.consumerService, ga.revocationService, ga.certStore).ClientCertAuth(),
+		middleware.NewHMACAuthMiddleware(ga.consumerService, ga.PebbleDB).HMACAuth(),
+		middleware.NewJWTAuthMiddleware(ga.jwtIssuerService, ga.consumerService).JWTAuth(),
+		middleware.NewRateLimitMiddleware(ga.rateLimitService, rateLimitStore).RateLimit(),
+		middleware.NewQuotaMiddleware(services.NewQuotaService(quotaLocation())).Quota(),
+		middleware.NewIdempotencyMiddleware(ga.PebbleDB).Idempotency(),
+		middleware.NewTrafficMiddleware(services.NewTrafficService()).TrafficStatsMiddleware(),
+		middleware.NewCompressionMiddleware(compressionOptions(CONFIG.Gateway.Compression)).Compression(),
+		middleware.NewHeaderTransformMiddleware(ga.downstreamService).HeaderTransform(),
+	)
 }
 
 // SetupRoutes设置网关转发应用的路由
 func (ga *GatewayApp) SetupRoutes() {
-	ga.Router.Any("/:name/*path", func(c *gin.Context) {
-		name := c.Param("name")
-		fmt.Println("name =>", name)
-		parts := c.Param("path")
-		if len(parts) > 0 {
-			serviceName := parts[1:]
-			service, err := ga.downstreamService.GetByName(context.Background(), serviceName)
+	ga.Router.Any("/*path", func(c *gin.Context) {
+		route := middleware.GetRoute(c)
+		if route != nil && route.BatchLimit > 0 {
+			ga.batchRequest(c, route)
+			return
+		}
+		if route != nil && len(route.Aggregation) > 0 {
+			ga.aggregateRequest(c, route)
+			return
+		}
+		if route != nil {
+			service, err := ga.downstreamService.GetByName(context.Background(), route.Downstream)
 			if err == nil && service.URL != "" {
 				backendURL, err := url.Parse(service.URL)
 				if err == nil {
-					// 创建一个自定义的转发器，这里可以根据需要调整转发逻辑
-					proxyClient := NewProxyClient(backendURL)
+					tlsConfig, err := newUpstreamTLSConfig(service, ga.certStore)
+					if err != nil {
+						log.Printf("Error building upstream tls config: %v", err)
+						c.JSON(http.StatusBadGateway, gin.H{"error": "Invalid upstream tls config"})
+						return
+					}
+					// 同一下游复用代理客户端及其连接池
+					proxyClient := ga.clients.client(service, tlsConfig)
 					// 记录入栈流量
 					// ga.Logger.LogTraffic(c.Request, true)
-					ga.forwardRequest(c, proxyClient)
+					ga.forwardRequest(c, proxyClient, service, backendURL)
 					// 记录出栈流量
 					// ga.Logger.LogTraffic(c.Request, false)
 					return
 				}
 			}
 		}
-		c.JSON(http.StatusOK, gin.H{"message": "Service not found"})
+		c.JSON(http.StatusNotFound, gin.H{"message": "Service not found"})
 	})
 
 	// 没有找到对应服务，返回404错误
@@ -82,11 +171,70 @@ func (ga *GatewayApp) SetupRoutes() {
 
 // Run启动网关转发应用
 func (ga *GatewayApp) Run() {
-	fmt.Println("API Gateway started on :8080")
-	err := ga.Router.Run(":8080")
+	config := CONFIG.Gateway
+	var handler http.Handler = ga.Router
+	if config.TLS.Enabled {
+		tlsConfig, err := newListenerTLSConfig(config.TLS, ga.certStore, newConfigCertStore())
+		if err != nil {
+			fmt.Printf("Error creating gateway tls config: %v", err)
+			return
+		}
+		go func() {
+			ln, err := ga.listen(config.TLS.Addr)
+			if err != nil {
+				fmt.Printf("Error starting gateway https server: %v", err)
+				return
+			}
+			server := &http.Server{Handler: ga.Router, TLSConfig: tlsConfig}
+			fmt.Printf("API Gateway started on %s (https)\n", config.TLS.Addr)
+			err = server.ServeTLS(ln, "", "")
+			if err != nil {
+				fmt.Printf("Error starting gateway https server: %v", err)
+			}
+		}()
+		if config.TLS.RedirectHTTP {
+			handler = httpsRedirectHandler(config.TLS.Addr)
+		}
+	}
+
+	ln, err := ga.listen(config.Addr)
 	if err != nil {
 		fmt.Printf("Error starting gateway server: %v", err)
+		return
 	}
+	fmt.Printf("API Gateway started on %s\n", config.Addr)
+	err = http.Serve(ln, handler)
+	if err != nil {
+		fmt.Printf("Error starting gateway server: %v", err)
+	}
+}
+
+// listen创建监听，启用PROXY协议时解析可信代理发送的PROXY协议头
+func (ga *GatewayApp) listen(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if !CONFIG.Gateway.ProxyProtocol {
+		return ln, nil
+	}
+
+	trusted, err := ipacl.ParsePrefixes(CONFIG.Gateway.TrustedProxies)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return &proxyproto.Listener{
+		Listener: ln,
+		Trusted: func(addr net.Addr) bool {
+			tcpAddr, ok := addr.(*net.TCPAddr)
+			if !ok {
+				return false
+			}
+			ip, ok := netip.AddrFromSlice(tcpAddr.IP)
+			return ok && ipacl.Contains(trusted, ip)
+		},
+	}, nil
 }
 
 // closePebbleDB关闭pebble数据库
@@ -96,9 +244,13 @@ func (ga *GatewayApp) Close() {
 	}
 }
 
-// storeAPIInfoInPebble将API信息存储到pebble数据库中
+// storeAPIInfoInPebble将API信息加密后存储到pebble数据库中
 func (ga *GatewayApp) storeAPIInfoInPebble(key, value string) error {
-	return ga.PebbleDB.Set([]byte(key), []byte(value), pebble.Sync)
+	sealed, err := secret.Seal([]byte(value))
+	if err != nil {
+		return err
+	}
+	return ga.PebbleDB.Set([]byte(key), sealed, pebble.Sync)
 }
 
 // NewProxy创建一个自定义的请求转发器，这里只是一个简单示例，可以根据实际需求扩展
@@ -113,15 +265,17 @@ func NewProxy(u *url.URL) *http.Transport {
 	}
 }
 
-// NewProxyClient创建一个自定义的代理客户端
-func NewProxyClient(targetURL *url.URL) *http.Client {
+// NewProxyClient创建一个自定义的代理客户端，连接请求地址中的主机，
+// 地址未指定端口时按协议使用默认端口，请求取消或超时时中止连接，空闲连接保留90秒供后续请求复用
+func NewProxyClient(tlsConfig *tls.Config) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
 	transport := &http.Transport{
-		Proxy: func(request *http.Request) (*url.URL, error) {
-			return targetURL, nil
-		},
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return net.Dial(network, targetURL.Host)
-		},
+		TLSClientConfig:     tlsConfig,
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
 	}
 	return &http.Client{Transport: transport}
 }
@@ -140,52 +294,151 @@ type ResponseInfo struct {
 	Body       []byte
 }
 
+// upstreamFunc 向下游服务发送请求，返回的响应体已完整读取，可以在处理函数返回后调用
+type upstreamFunc func(req *http.Request) (*http.Response, error)
+
+// forwardError 转发失败，Error为返回给客户端的错误信息
+type forwardError struct {
+	message string
+}
+
+func (e *forwardError) Error() string { return e.message }
+
 // forwardRequest用于转发请求
-func (ga *GatewayApp) forwardRequest(c *gin.Context, client *http.Client) {
+func (ga *GatewayApp) forwardRequest(c *gin.Context, client *http.Client, service *model.Downstream, backendURL *url.URL) {
+	route := middleware.GetRoute(c)
 	req := c.Request.Clone(c.Request.Context())
-	requestInfo, err := extractRequestInfo(req)
-	if err != nil {
-		log.Printf("Error extracting request info: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to extract request info"})
+	if err := rewriteRequest(c, route, req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request path"})
 		return
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to forward request"})
+	if err := transformRequestBody(route, req); err != nil {
+		if errors.Is(err, compress.ErrTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON request body"})
 		return
 	}
-	defer resp.Body.Close()
+	req.RequestURI = ""
+	req.URL.Scheme = backendURL.Scheme
+	req.URL.Host = backendURL.Host
+	req.URL.Path = singleJoiningSlash(backendURL.Path, req.URL.Path)
+	req.URL.RawPath = ""
+	req.Host = backendURL.Host
 
-	responseInfo, err := extractResponseInfo(resp, requestInfo.Method)
+	bridge, err := ga.soap.bridge(route)
 	if err != nil {
-		log.Printf("Error extracting response info: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to extract response info"})
+		log.Printf("Error loading soap bridge %s: %v", route.SOAPBridge, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to load soap bridge"})
+		return
+	}
+	upstream := ga.upstream(client, service, route)
+	if bridge != nil {
+		if err := soapRequest(c, bridge, req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to convert request to soap: " + err.Error()})
+			return
+		}
+		upstream = soapResponse(bridge, upstream)
+	}
+	upstream = ga.coalescer.wrap(route, middleware.GetConsumer(c), transformResponse(route, upstream))
+	if policy := ga.cache.policy(route); policy != nil {
+		ga.cache.serve(c, route, policy, req, upstream)
 		return
 	}
+	forwardResponse(c, req, upstream)
+}
 
-	err = storeRequestInfo(ga, requestInfo)
-	if err != nil {
-		log.Printf("Error storing request info in Pebble: %v", err)
+// upstream返回向下游服务发送请求的函数，请求在发送前签名，请求和响应脱敏后记录
+func (ga *GatewayApp) upstream(client *http.Client, service *model.Downstream, route *model.APIInfo) upstreamFunc {
+	redactor := ga.redactor.redactor(route)
+	return func(req *http.Request) (*http.Response, error) {
+		requestInfo, err := extractRequestInfo(req)
+		if err != nil {
+			log.Printf("Error extracting request info: %v", err)
+			return nil, &forwardError{message: "Failed to extract request info"}
+		}
+
+		// 签名需要在所有请求头处理完成后进行
+		err = signRequest(req, requestInfo.Body, service.Signing)
+		if err != nil {
+			log.Printf("Error signing request: %v", err)
+			return nil, &forwardError{message: "Failed to sign request"}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, &forwardError{message: "Failed to forward request"}
+		}
+		defer resp.Body.Close()
+
+		responseInfo, err := extractResponseInfo(resp, requestInfo.Method)
+		if err != nil {
+			log.Printf("Error extracting response info: %v", err)
+			return nil, &forwardError{message: "Failed to extract response info"}
+		}
+
+		// 记录的流量在写入前脱敏，请求头中包含签名后添加的凭证
+		err = storeRequestInfo(ga, redactRequestInfo(redactor, requestInfo))
+		if err != nil {
+			log.Printf("Error storing request info in Pebble: %v", err)
+		}
+
+		err = storeResponseInfo(ga, redactResponseInfo(redactor, responseInfo))
+		if err != nil {
+			log.Printf("Error storing response info in Pebble: %v", err)
+		}
+		return resp, nil
 	}
+}
 
-	err = storeResponseInfo(ga, responseInfo)
+// forwardResponse请求下游服务并把响应写回客户端
+func forwardResponse(c *gin.Context, req *http.Request, upstream upstreamFunc) {
+	resp, err := upstream(req)
 	if err != nil {
-		log.Printf("Error storing response info in Pebble: %v", err)
+		writeForwardError(c, err)
+		return
+	}
+	copyResponse(c, resp)
+}
+
+// writeForwardError返回转发失败的错误
+func writeForwardError(c *gin.Context, err error) {
+	message := "Failed to forward request"
+	var fe *forwardError
+	if errors.As(err, &fe) {
+		message = fe.message
 	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
 
+// copyResponse把下游响应写回客户端
+func copyResponse(c *gin.Context, resp *http.Response) {
 	for k, vv := range resp.Header {
 		for _, v := range vv {
 			c.Writer.Header().Add(k, v)
 		}
 	}
 	c.Writer.WriteHeader(resp.StatusCode)
-	_, err = io.Copy(c.Writer, resp.Body)
+	_, err := io.Copy(c.Writer, resp.Body)
 	if err != nil {
 		fmt.Println("Error copying response body:", err)
 	}
 }
 
+// singleJoiningSlash拼接下游地址路径和请求路径
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
+
 // 从请求中提取请求信息，包括请求体
 func extractRequestInfo(req *http.Request) (RequestInfo, error) {
 	var requestBody []byte
@@ -196,6 +449,7 @@ func extractRequestInfo(req *http.Request) (RequestInfo, error) {
 		}
 		requestBody = reqBody
 		req.Body = io.NopCloser(bytes.NewBuffer(reqBody)) // 重置请求体，以便后续转发
+		req.ContentLength = int64(len(reqBody))
 	}
 	return RequestInfo{
 		Method:  req.Method,
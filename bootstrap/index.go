@@ -5,6 +5,7 @@ import (
 	"api-gateway/internal/model"
 	"api-gateway/pkg/db"
 	"api-gateway/pkg/logger"
+	"api-gateway/pkg/secret"
 	"fmt"
 	"path/filepath"
 )
@@ -12,8 +13,12 @@ import (
 func Run() {
 	// 初始化运行路径
 	InitRuntime()
+	// 初始化配置
+	InitConfig()
 	// 初始化日志
 	InitLogger()
+	// 初始化加密密钥
+	InitSecret()
 	// 初始化数据库
 	InitDB()
 	// 启动网关服务
@@ -48,6 +53,44 @@ func InitLogger() {
 	global.Logger = logger.InitLogger(path)
 }
 
+func InitSecret() {
+	keys, err := secret.LoadKeys(masterKeyPath())
+	if err != nil {
+		panic(fmt.Sprintf("加载主密钥失败: %v", err))
+	}
+	if err := secret.SetKeys(keys); err != nil {
+		panic(fmt.Sprintf("设置主密钥失败: %v", err))
+	}
+}
+
+// masterKeyPath返回主密钥文件路径
+func masterKeyPath() string {
+	return filepath.Join(CONFIG_PATH, "master.key")
+}
+
+// models 需要迁移的数据模型
+var models = []any{
+	&model.APIInfo{},
+	&model.Downstream{},
+	&model.TrafficStats{},
+	&model.Consumer{},
+	&model.Certificate{},
+	&model.RevokedCertificate{},
+	&model.IPAccessList{},
+	&model.CORSPolicy{},
+	&model.ThreatPolicy{},
+	&model.WAFRule{},
+	&model.WAFPolicy{},
+	&model.RedactionPolicy{},
+	&model.AuditLog{},
+	&model.RateLimitPolicy{},
+	&model.UsagePlan{},
+	&model.QuotaUsage{},
+	&model.CachePolicy{},
+	&model.SOAPBridge{},
+	&model.JWTIssuer{},
+}
+
 func InitDB() {
 	path := filepath.Join(DB_PATH, "data.db")
 	var err error
@@ -56,9 +99,5 @@ func InitDB() {
 		panic(fmt.Sprintf("初始化[sqlite]数据库失败: %v", err))
 	}
 
-	global.DB.AutoMigrate(
-		&model.APIInfo{},
-		&model.Downstream{},
-		&model.TrafficStats{},
-	)
+	global.DB.AutoMigrate(models...)
 }
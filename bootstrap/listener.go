@@ -0,0 +1,98 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"api-gateway/internal/global"
+	"api-gateway/pkg/certstore"
+
+	"go.uber.org/zap"
+)
+
+// 证书文件变化检查间隔
+const certWatchInterval = 5 * time.Second
+
+// newConfigCertStore创建配置目录中的证书存储，用于手动放置的<name>.crt和<name>.key
+func newConfigCertStore() *certstore.Store {
+	store, err := certstore.NewStore(CONFIG_PATH)
+	if err != nil {
+		panic(fmt.Sprintf("初始化证书目录失败: %v", err))
+	}
+	return store
+}
+
+// newListenerTLSConfig创建网关HTTPS监听使用的TLS配置，按SNI选择证书并在证书变化时热加载，
+// 客户端CA证书从第一个证书存储中加载
+func newListenerTLSConfig(config GatewayTLSConfig, stores ...*certstore.Store) (*tls.Config, error) {
+	resolver := certstore.NewSNIResolver(config.Certificates, stores...)
+	resolver.OnReload = warnExpiringCertificates
+	if err := resolver.Reload(); err != nil {
+		global.Logger.Error("加载证书失败", zap.Error(err))
+	}
+	resolver.Watch(certWatchInterval, func(err error) {
+		global.Logger.Error("重新加载证书失败", zap.Error(err))
+	})
+
+	tlsConfig := &tls.Config{
+		GetCertificate: resolver.GetCertificate,
+	}
+	if config.MinVersion != "" {
+		version, err := parseTLSVersion(config.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	switch {
+	case config.ClientCA != "":
+		// 每次握手时重新获取CA证书，使CA证书的更新无需重启即可生效
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		base := tlsConfig.Clone()
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			pool, err := stores[0].CertPool(config.ClientCA)
+			if err != nil {
+				return nil, err
+			}
+			clientConfig := base.Clone()
+			clientConfig.ClientCAs = pool
+			return clientConfig, nil
+		}
+	case config.RequestClientCert:
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	}
+	return tlsConfig, nil
+}
+
+// warnExpiringCertificates对即将到期的证书记录告警日志
+func warnExpiringCertificates(certs map[string]*x509.Certificate) {
+	window := time.Duration(CONFIG.Management.CertExpiryWarning) * 24 * time.Hour
+	for name, leaf := range certs {
+		if time.Until(leaf.NotAfter) < window {
+			global.Logger.Warn("证书即将到期",
+				zap.String("certificate", name),
+				zap.Time("not_after", leaf.NotAfter))
+		}
+	}
+}
+
+// httpsRedirectHandler将HTTP请求重定向到HTTPS监听地址
+func httpsRedirectHandler(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
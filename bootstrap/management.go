@@ -1,9 +1,13 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"time"
 
 	"api-gateway/internal/api"
+	"api-gateway/internal/middleware"
 	"api-gateway/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +19,23 @@ type ManagementApp struct {
 	VersionGroup *gin.RouterGroup
 	API          *api.APIController
 	DOWNStream   *api.DownstreamController
+	Consumer     *api.ConsumerController
+	Certificate  *api.CertificateController
+	Revocation   *api.RevocationController
+	IPAccess     *api.IPAccessListController
+	CORS         *api.CORSPolicyController
+	Threat       *api.ThreatPolicyController
+	WAFPolicy    *api.WAFPolicyController
+	WAFRule      *api.WAFRuleController
+	Redaction    *api.RedactionPolicyController
+	Audit        *api.AuditController
+	RateLimit    *api.RateLimitPolicyController
+	UsagePlan    *api.UsagePlanController
+	Quota        *api.QuotaController
+	Cache        *api.CachePolicyController
+	SOAP         *api.SOAPBridgeController
+	JWTIssuer    *api.JWTIssuerController
+	auditService services.AuditLogServiceImpl
 }
 
 // NewManagementApp创建并初始化用于管理的应用实例
@@ -29,8 +50,59 @@ func (ma *ManagementApp) Initialize() {
 
 	dsService := services.NewDownstreamService()
 	ma.DOWNStream = api.NewDownstreamController(dsService)
+
+	consumerService := services.NewConsumerService()
+	ma.Consumer = api.NewConsumerController(consumerService)
+
+	certService := services.NewCertificateService()
+	expiryWindow := time.Duration(CONFIG.Management.CertExpiryWarning) * 24 * time.Hour
+	ma.Certificate = api.NewCertificateController(certService, newCertStore(), newConfigCertStore(), expiryWindow)
+
+	revocationService := services.NewRevokedCertificateService()
+	ma.Revocation = api.NewRevocationController(revocationService)
+
+	ipAccessService := services.NewIPAccessListService()
+	ma.IPAccess = api.NewIPAccessListController(ipAccessService)
+
+	corsService := services.NewCORSPolicyService()
+	ma.CORS = api.NewCORSPolicyController(corsService)
+
+	threatService := services.NewThreatPolicyService()
+	ma.Threat = api.NewThreatPolicyController(threatService)
+
+	wafPolicyService := services.NewWAFPolicyService()
+	ma.WAFPolicy = api.NewWAFPolicyController(wafPolicyService)
+	wafRuleService := services.NewWAFRuleService()
+	ma.WAFRule = api.NewWAFRuleController(wafRuleService)
+
+	redactionService := services.NewRedactionPolicyService()
+	ma.Redaction = api.NewRedactionPolicyController(redactionService)
+
+	rateLimitService := services.NewRateLimitPolicyService()
+	ma.RateLimit = api.NewRateLimitPolicyController(rateLimitService)
+
+	usagePlanService := services.NewUsagePlanService()
+	ma.UsagePlan = api.NewUsagePlanController(usagePlanService)
+	quotaService := services.NewQuotaService(quotaLocation())
+	ma.Quota = api.NewQuotaController(quotaService)
+
+	cacheService := services.NewCachePolicyService()
+	ma.Cache = api.NewCachePolicyController(cacheService)
+	soapService := services.NewSOAPBridgeService()
+	ma.SOAP = api.NewSOAPBridgeController(soapService)
+
+	jwtIssuerService := services.NewJWTIssuerService()
+	ma.JWTIssuer = api.NewJWTIssuerController(jwtIssuerService)
+
+	ma.auditService = services.NewAuditLogService()
+	ma.Audit = api.NewAuditController(ma.auditService)
+
 	ma.Router = gin.Default()
+	if err := ma.Router.SetTrustedProxies(CONFIG.Management.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("设置可信代理失败: %v", err))
+	}
 	ma.VersionGroup = ma.Router.Group("api/v1")
+	ma.VersionGroup.Use(middleware.NewAuditMiddleware(ma.auditService, ma.Router, ma.VersionGroup.BasePath(), CONFIG.Management.Audit.ActorHeader).Audit())
 }
 
 // SetupRoutes设置管理应用的路由
@@ -51,13 +123,155 @@ func (ma *ManagementApp) SetupRoutes() {
 		dsRoutes.PUT("/:name", ma.DOWNStream.Update)
 		dsRoutes.DELETE("/:name", ma.DOWNStream.Delete)
 	}
+	consumerRoutes := ma.VersionGroup.Group("/consumers")
+	{
+		consumerRoutes.POST("", ma.Consumer.Create)
+		consumerRoutes.GET("", ma.Consumer.List)
+		consumerRoutes.GET("/:name", ma.Consumer.GetByName)
+		consumerRoutes.PUT("/:name", ma.Consumer.Update)
+		consumerRoutes.DELETE("/:name", ma.Consumer.Delete)
+	}
+	certRoutes := ma.VersionGroup.Group("/certificates")
+	{
+		certRoutes.POST("", ma.Certificate.Create)
+		certRoutes.GET("", ma.Certificate.List)
+		certRoutes.GET("/expiring", ma.Certificate.Expiring)
+		certRoutes.GET("/:name", ma.Certificate.GetByName)
+		certRoutes.PUT("/:name", ma.Certificate.Update)
+		certRoutes.DELETE("/:name", ma.Certificate.Delete)
+	}
+	revocationRoutes := ma.VersionGroup.Group("/revocations")
+	{
+		revocationRoutes.POST("", ma.Revocation.Create)
+		revocationRoutes.GET("", ma.Revocation.List)
+		revocationRoutes.GET("/:serial", ma.Revocation.GetBySerialNumber)
+		revocationRoutes.DELETE("/:serial", ma.Revocation.Delete)
+	}
+	ipAccessRoutes := ma.VersionGroup.Group("/ip-access-lists")
+	{
+		ipAccessRoutes.POST("", ma.IPAccess.Create)
+		ipAccessRoutes.GET("", ma.IPAccess.List)
+		ipAccessRoutes.GET("/:name", ma.IPAccess.GetByName)
+		ipAccessRoutes.PUT("/:name", ma.IPAccess.Update)
+		ipAccessRoutes.DELETE("/:name", ma.IPAccess.Delete)
+	}
+	corsRoutes := ma.VersionGroup.Group("/cors-policies")
+	{
+		corsRoutes.POST("", ma.CORS.Create)
+		corsRoutes.GET("", ma.CORS.List)
+		corsRoutes.GET("/:name", ma.CORS.GetByName)
+		corsRoutes.PUT("/:name", ma.CORS.Update)
+		corsRoutes.DELETE("/:name", ma.CORS.Delete)
+	}
+	threatRoutes := ma.VersionGroup.Group("/threat-policies")
+	{
+		threatRoutes.POST("", ma.Threat.Create)
+		threatRoutes.GET("", ma.Threat.List)
+		threatRoutes.GET("/:name", ma.Threat.GetByName)
+		threatRoutes.PUT("/:name", ma.Threat.Update)
+		threatRoutes.DELETE("/:name", ma.Threat.Delete)
+	}
+	wafPolicyRoutes := ma.VersionGroup.Group("/waf-policies")
+	{
+		wafPolicyRoutes.POST("", ma.WAFPolicy.Create)
+		wafPolicyRoutes.GET("", ma.WAFPolicy.List)
+		wafPolicyRoutes.GET("/:name", ma.WAFPolicy.GetByName)
+		wafPolicyRoutes.PUT("/:name", ma.WAFPolicy.Update)
+		wafPolicyRoutes.DELETE("/:name", ma.WAFPolicy.Delete)
+	}
+	wafRuleRoutes := ma.VersionGroup.Group("/waf-rules")
+	{
+		wafRuleRoutes.POST("", ma.WAFRule.Create)
+		wafRuleRoutes.GET("", ma.WAFRule.List)
+		wafRuleRoutes.GET("/builtin", ma.WAFRule.Builtin)
+		wafRuleRoutes.GET("/:id", ma.WAFRule.GetByRuleID)
+		wafRuleRoutes.PUT("/:id", ma.WAFRule.Update)
+		wafRuleRoutes.DELETE("/:id", ma.WAFRule.Delete)
+	}
+	redactionRoutes := ma.VersionGroup.Group("/redaction-policies")
+	{
+		redactionRoutes.POST("", ma.Redaction.Create)
+		redactionRoutes.GET("", ma.Redaction.List)
+		redactionRoutes.GET("/:name", ma.Redaction.GetByName)
+		redactionRoutes.PUT("/:name", ma.Redaction.Update)
+		redactionRoutes.DELETE("/:name", ma.Redaction.Delete)
+	}
+	rateLimitRoutes := ma.VersionGroup.Group("/rate-limit-policies")
+	{
+		rateLimitRoutes.POST("", ma.RateLimit.Create)
+		rateLimitRoutes.GET("", ma.RateLimit.List)
+		rateLimitRoutes.GET("/:name", ma.RateLimit.GetByName)
+		rateLimitRoutes.PUT("/:name", ma.RateLimit.Update)
+		rateLimitRoutes.DELETE("/:name", ma.RateLimit.Delete)
+	}
+	usagePlanRoutes := ma.VersionGroup.Group("/usage-plans")
+	{
+		usagePlanRoutes.POST("", ma.UsagePlan.Create)
+		usagePlanRoutes.GET("", ma.UsagePlan.List)
+		usagePlanRoutes.GET("/:name", ma.UsagePlan.GetByName)
+		usagePlanRoutes.PUT("/:name", ma.UsagePlan.Update)
+		usagePlanRoutes.DELETE("/:name", ma.UsagePlan.Delete)
+	}
+	quotaRoutes := ma.VersionGroup.Group("/quotas")
+	{
+		quotaRoutes.GET("/:consumer", ma.Quota.GetByConsumer)
+		quotaRoutes.POST("/:consumer/reset", ma.Quota.Reset)
+	}
+	cacheRoutes := ma.VersionGroup.Group("/cache-policies")
+	{
+		cacheRoutes.POST("", ma.Cache.Create)
+		cacheRoutes.GET("", ma.Cache.List)
+		cacheRoutes.GET("/:name", ma.Cache.GetByName)
+		cacheRoutes.PUT("/:name", ma.Cache.Update)
+		cacheRoutes.DELETE("/:name", ma.Cache.Delete)
+	}
+	soapRoutes := ma.VersionGroup.Group("/soap-bridges")
+	{
+		soapRoutes.POST("", ma.SOAP.Create)
+		soapRoutes.GET("", ma.SOAP.List)
+		soapRoutes.GET("/:name", ma.SOAP.GetByName)
+		soapRoutes.PUT("/:name", ma.SOAP.Update)
+		soapRoutes.DELETE("/:name", ma.SOAP.Delete)
+		soapRoutes.POST("/:name/dry-run", ma.SOAP.DryRun)
+	}
+	jwtIssuerRoutes := ma.VersionGroup.Group("/jwt-issuers")
+	{
+		jwtIssuerRoutes.POST("", ma.JWTIssuer.Create)
+		jwtIssuerRoutes.GET("", ma.JWTIssuer.List)
+		jwtIssuerRoutes.GET("/:name", ma.JWTIssuer.GetByName)
+		jwtIssuerRoutes.PUT("/:name", ma.JWTIssuer.Update)
+		jwtIssuerRoutes.DELETE("/:name", ma.JWTIssuer.Delete)
+	}
+	auditRoutes := ma.VersionGroup.Group("/audit")
+	{
+		auditRoutes.GET("", ma.Audit.List)
+		auditRoutes.GET("/:id", ma.Audit.GetById)
+	}
 }
 
 // Run启动管理应用
 func (ma *ManagementApp) Run() {
-	fmt.Println("Management API started on :8081")
-	err := ma.Router.Run(":8081")
+	if days := CONFIG.Management.Audit.RetentionDays; days > 0 {
+		go ma.purgeAuditLogs(time.Duration(days) * 24 * time.Hour)
+	}
+	addr := CONFIG.Management.Addr
+	fmt.Printf("Management API started on %s\n", addr)
+	err := ma.Router.Run(addr)
 	if err != nil {
 		fmt.Printf("Error starting management server: %v", err)
 	}
 }
+
+// purgeAuditLogs定期删除超过保留时间的审计记录
+func (ma *ManagementApp) purgeAuditLogs(retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		count, err := ma.auditService.Purge(context.Background(), time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Error purging audit logs: %v", err)
+		} else if count > 0 {
+			log.Printf("Purged %d audit logs older than %s", count, retention)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"sync"
+	"time"
+
+	"api-gateway/internal/model"
+)
+
+// proxyClients 按下游服务复用的代理客户端，同一下游的请求共享连接池
+type proxyClients struct {
+	mu      sync.Mutex
+	clients map[string]cachedProxyClient
+}
+
+// cachedProxyClient 代理客户端及创建时下游的更新时间和证书，任意一项变化后重新创建
+// 证书存储在文件未变化时返回同一个解析结果，因此按指针比较即可发现证书的更新
+type cachedProxyClient struct {
+	updatedAt time.Time
+	rootCAs   *x509.CertPool
+	leaf      *x509.Certificate
+	client    *http.Client
+}
+
+func newProxyClients() *proxyClients {
+	return &proxyClients{clients: make(map[string]cachedProxyClient)}
+}
+
+// client返回下游服务的代理客户端，tlsConfig为根据下游配置生成的TLS配置
+func (pc *proxyClients) client(service *model.Downstream, tlsConfig *tls.Config) *http.Client {
+	var leaf *x509.Certificate
+	if len(tlsConfig.Certificates) > 0 {
+		leaf = tlsConfig.Certificates[0].Leaf
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if cached, ok := pc.clients[service.Name]; ok {
+		if cached.updatedAt.Equal(service.UpdatedAt) && cached.rootCAs == tlsConfig.RootCAs && cached.leaf == leaf {
+			return cached.client
+		}
+		// 旧客户端上进行中的请求不受影响，空闲连接立即关闭
+		cached.client.CloseIdleConnections()
+	}
+	client := NewProxyClient(tlsConfig)
+	pc.clients[service.Name] = cachedProxyClient{
+		updatedAt: service.UpdatedAt,
+		rootCAs:   tlsConfig.RootCAs,
+		leaf:      leaf,
+		client:    client,
+	}
+	return client
+}
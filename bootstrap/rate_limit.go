@@ -0,0 +1,30 @@
+package bootstrap
+
+import (
+	"log"
+	"time"
+
+	"api-gateway/pkg/ratelimit"
+	"api-gateway/pkg/resp"
+)
+
+// newRateLimitStore创建限流存储，配置了共享后端时使用集群限流，后端不可用时退回本地限流
+func newRateLimitStore(config RateLimitConfig) (ratelimit.Store, error) {
+	local := ratelimit.NewMemoryStore(config.MaxKeys)
+	if config.Backend == "" {
+		return local, nil
+	}
+	client, err := resp.NewClient(config.Backend, time.Duration(config.TimeoutMs)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewSharedStore(client, local, ratelimit.SharedOptions{
+		Prefix:       "gateway:",
+		BatchSize:    config.BatchSize,
+		SyncInterval: time.Duration(config.SyncIntervalMs) * time.Millisecond,
+		MaxKeys:      config.MaxKeys,
+		OnError: func(err error) {
+			log.Printf("Rate limit backend unavailable, falling back to local limits: %v", err)
+		},
+	}), nil
+}
@@ -0,0 +1,32 @@
+package bootstrap
+
+import (
+	"log"
+	"net/url"
+
+	"api-gateway/internal/middleware"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/transform"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rewriteRequest按路由的改写配置修改转发地址的路径和查询参数
+// 配置在保存时已经校验过，编译失败时记录日志并按原路径转发
+func rewriteRequest(c *gin.Context, route *model.APIInfo, u *url.URL) error {
+	if route.RewritePath == "" && route.StripPrefix == "" && route.AddPrefix == "" && len(route.QueryRules) == 0 {
+		return nil
+	}
+	pattern, err := services.CompilePathPattern(route.Path)
+	if err != nil {
+		log.Printf("Error compiling path pattern for %s: %v", route.Name, err)
+		return nil
+	}
+	rewrite, err := transform.CompileRewrite(route.RewriteOptions(pattern.Params()))
+	if err != nil {
+		log.Printf("Error compiling rewrite for %s: %v", route.Name, err)
+		return nil
+	}
+	return rewrite.Apply(u, middleware.NewRequestVars(c))
+}
@@ -0,0 +1,40 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-gateway/internal/model"
+	"api-gateway/pkg/signature"
+)
+
+const (
+	// SigningAWSV4 AWS Signature Version 4签名
+	SigningAWSV4 = "aws-sigv4"
+	// SigningHMAC 通用HMAC签名
+	SigningHMAC = "hmac"
+)
+
+// signRequest根据下游的签名配置对转发请求进行签名，需要在所有请求头处理完成后调用
+func signRequest(req *http.Request, body []byte, profile model.SigningProfile) error {
+	switch profile.Type {
+	case "":
+		return nil
+	case SigningAWSV4:
+		signature.SignV4(req, body, signature.AWSCredentials{
+			AccessKey:    profile.AccessKey,
+			SecretKey:    profile.SecretKey,
+			SessionToken: profile.SessionToken,
+		}, profile.Region, profile.Service)
+		return nil
+	case SigningHMAC:
+		var headers []string
+		if profile.SignedHeaders != "" {
+			headers = strings.Split(profile.SignedHeaders, ";")
+		}
+		return signature.SignHMAC(req, body, profile.AccessKey, profile.SecretKey, headers)
+	default:
+		return fmt.Errorf("unsupported signing type %q", profile.Type)
+	}
+}
@@ -0,0 +1,148 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/internal/middleware"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/soap"
+
+	"github.com/gin-gonic/gin"
+)
+
+// soapBridges 路由使用的SOAP桥接
+type soapBridges struct {
+	service services.SOAPBridgeServiceImpl
+
+	mu       sync.Mutex
+	compiled map[string]compiledSOAPBridge
+}
+
+// compiledSOAPBridge 编译后的桥接及其更新时间，桥接更新后重新编译
+type compiledSOAPBridge struct {
+	updatedAt time.Time
+	bridge    *soap.Bridge
+}
+
+func newSOAPBridges(service services.SOAPBridgeServiceImpl) *soapBridges {
+	return &soapBridges{service: service, compiled: make(map[string]compiledSOAPBridge)}
+}
+
+// bridge返回路由使用的SOAP桥接，未配置时返回nil
+func (sb *soapBridges) bridge(route *model.APIInfo) (*soap.Bridge, error) {
+	if route == nil || route.SOAPBridge == "" {
+		return nil, nil
+	}
+	data, err := sb.service.GetByName(context.Background(), route.SOAPBridge)
+	if err != nil {
+		return nil, err
+	}
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if cached, ok := sb.compiled[data.Name]; ok && cached.updatedAt.Equal(data.UpdatedAt) {
+		return cached.bridge, nil
+	}
+	bridge, err := soap.Compile(data.SOAPOptions())
+	if err != nil {
+		return nil, err
+	}
+	sb.compiled[data.Name] = compiledSOAPBridge{updatedAt: data.UpdatedAt, bridge: bridge}
+	return bridge, nil
+}
+
+// soapRequest把JSON请求转换为SOAP请求
+func soapRequest(c *gin.Context, bridge *soap.Bridge, req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		body = data
+	}
+	if encoding := req.Header.Get("Content-Encoding"); encoding != "" && len(body) > 0 {
+		decoded, err := compress.Decode(encoding, body, CONFIG.Gateway.Compression.MaxDecodedSize)
+		if err != nil {
+			return err
+		}
+		body = decoded
+	}
+	var doc any
+	if len(bytes.TrimSpace(body)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return err
+		}
+	}
+
+	envelope, err := bridge.Request(soap.Data{
+		Body:   doc,
+		Query:  firstValues(req.URL.Query()),
+		Path:   middleware.GetPathParams(c),
+		Header: firstValues(req.Header),
+	})
+	if err != nil {
+		return err
+	}
+	req.Method = http.MethodPost
+	req.Body = io.NopCloser(bytes.NewReader(envelope))
+	req.ContentLength = int64(len(envelope))
+	req.Header.Del("Content-Encoding")
+	req.Header.Set("Content-Length", strconv.Itoa(len(envelope)))
+	bridge.SetHeaders(req.Header)
+	return nil
+}
+
+// soapResponse返回把下游SOAP响应转换为JSON的upstreamFunc
+func soapResponse(bridge *soap.Bridge, upstream upstreamFunc) upstreamFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := upstream(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, &forwardError{message: "Failed to read soap response"}
+		}
+		if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+			if body, err = compress.Decode(encoding, body, CONFIG.Gateway.Compression.MaxDecodedSize); err != nil {
+				return nil, &forwardError{message: "Failed to decode soap response"}
+			}
+		}
+		status, converted := bridge.Response(resp.StatusCode, body)
+		resp.StatusCode = status
+		resp.Status = strconv.Itoa(status) + " " + http.StatusText(status)
+		resp.Body = io.NopCloser(bytes.NewReader(converted))
+		resp.ContentLength = int64(len(converted))
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		resp.Header.Set("Content-Length", strconv.Itoa(len(converted)))
+		if etag := resp.Header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			resp.Header.Set("ETag", "W/"+etag)
+		}
+		return resp, nil
+	}
+}
+
+// firstValues返回每个名称的第一个值
+func firstValues(values map[string][]string) map[string]string {
+	first := make(map[string]string, len(values))
+	for name, v := range values {
+		if len(v) > 0 {
+			first[name] = v[0]
+		}
+	}
+	return first
+}
@@ -0,0 +1,81 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+	"api-gateway/pkg/certstore"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newCertStore创建保存在数据目录中的证书存储
+func newCertStore() *certstore.Store {
+	store, err := certstore.NewStore(filepath.Join(DB_PATH, "certs"))
+	if err != nil {
+		panic(fmt.Sprintf("初始化证书目录失败: %v", err))
+	}
+	return store
+}
+
+// newUpstreamTLSConfig根据下游的TLS配置创建连接下游使用的TLS配置
+func newUpstreamTLSConfig(service *model.Downstream, store *certstore.Store) (*tls.Config, error) {
+	settings := service.TLS
+	config := &tls.Config{
+		ServerName: settings.ServerName,
+	}
+
+	if settings.MinVersion != "" {
+		version, err := parseTLSVersion(settings.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		config.MinVersion = version
+	}
+
+	if settings.CA != "" {
+		pool, err := store.CertPool(settings.CA)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if settings.ClientCert != "" {
+		cert, err := store.Certificate(settings.ClientCert)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{*cert}
+	}
+
+	if settings.InsecureSkipVerify {
+		if gin.Mode() == gin.ReleaseMode {
+			return nil, errors.New("insecure skip verify is not allowed in release mode")
+		}
+		global.Logger.Warn("下游跳过了TLS证书校验，仅可用于开发环境", zap.String("downstream", service.Name))
+		config.InsecureSkipVerify = true
+	}
+	return config, nil
+}
+
+// parseTLSVersion解析TLS版本号
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls version %q", version)
+	}
+}
@@ -2,10 +2,15 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
+	"api-gateway/internal/middleware"
 	"api-gateway/internal/model"
 	"api-gateway/internal/services"
+	"api-gateway/pkg/aggregate"
+	"api-gateway/pkg/transform"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,6 +32,10 @@ func (ac *APIController) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateAPIInfo(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	err := ac.service.Add(context.Background(), &api)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -64,6 +73,10 @@ func (ac *APIController) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateAPIInfo(&updatedAPI); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	err := ac.service.UpdateByName(context.Background(), updatedAPI, name)
 	if err != nil {
@@ -83,3 +96,45 @@ func (ac *APIController) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// validateAPIInfo校验API的路径模式、改写配置、聚合子请求、批量请求以及请求头、响应头和JSON转换规则
+func validateAPIInfo(api *model.APIInfo) error {
+	var params []string
+	if api.Path != "" {
+		pattern, err := transform.CompilePath(api.Path)
+		if err != nil {
+			return err
+		}
+		params = pattern.Params()
+	}
+	if _, err := transform.CompileRewrite(api.RewriteOptions(params)); err != nil {
+		return err
+	}
+	if api.AuthMode == middleware.AuthModeJWT && api.JWTIssuer == "" {
+		return errors.New("jwt auth requires a jwt issuer")
+	}
+	if api.BatchLimit < 0 || api.BatchConcurrency < 0 {
+		return errors.New("batch limit and concurrency must not be negative")
+	}
+	if api.BatchLimit > 0 && len(api.Aggregation) > 0 {
+		return errors.New("a route cannot be both a batch endpoint and an aggregation")
+	}
+	if len(api.Aggregation) > 0 {
+		if _, err := aggregate.Compile(api.Aggregation, params); err != nil {
+			return fmt.Errorf("invalid aggregation: %v", err)
+		}
+	}
+	if _, err := transform.CompileHeaderRules(api.RequestHeaders); err != nil {
+		return fmt.Errorf("invalid request header rules: %v", err)
+	}
+	if _, err := transform.CompileHeaderRules(api.ResponseHeaders); err != nil {
+		return fmt.Errorf("invalid response header rules: %v", err)
+	}
+	if _, err := transform.CompileBodyRules(api.RequestBody); err != nil {
+		return fmt.Errorf("invalid request body rules: %v", err)
+	}
+	if _, err := transform.CompileBodyRules(api.ResponseBody); err != nil {
+		return fmt.Errorf("invalid response body rules: %v", err)
+	}
+	return nil
+}
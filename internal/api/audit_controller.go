@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-gateway/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审计记录默认和最大返回条数
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditController struct {
+	service services.AuditLogServiceImpl
+}
+
+func NewAuditController(service services.AuditLogServiceImpl) *AuditController {
+	return &AuditController{
+		service: service,
+	}
+}
+
+// 查询审计记录，支持actor、resource、name、action、since、until（RFC3339）、limit、offset参数
+func (ac *AuditController) List(c *gin.Context) {
+	query := services.AuditQuery{
+		Actor:    c.Query("actor"),
+		Resource: c.Query("resource"),
+		Name:     c.Query("name"),
+		Action:   c.Query("action"),
+		Limit:    defaultAuditLimit,
+	}
+	var err error
+	if v := c.Query("since"); v != "" {
+		if query.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since"})
+			return
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if query.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until"})
+			return
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit <= 0 || query.Limit > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if query.Offset, err = strconv.Atoi(v); err != nil || query.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+	}
+
+	result, err := ac.service.Query(context.Background(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据ID获取审计记录
+func (ac *AuditController) GetById(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	data, err := ac.service.GetById(context.Background(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
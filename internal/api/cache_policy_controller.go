@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CachePolicyController struct {
+	service services.CachePolicyServiceImpl
+}
+
+func NewCachePolicyController(service services.CachePolicyServiceImpl) *CachePolicyController {
+	return &CachePolicyController{
+		service: service,
+	}
+}
+
+// 创建缓存策略
+func (ac *CachePolicyController) Create(c *gin.Context) {
+	var api model.CachePolicy
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateCachePolicy(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有缓存策略
+func (ac *CachePolicyController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取缓存策略
+func (ac *CachePolicyController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新缓存策略
+func (ac *CachePolicyController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.CachePolicy
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateCachePolicy(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除缓存策略
+func (ac *CachePolicyController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateCachePolicy校验缓存策略，时间和大小不能为负数
+func validateCachePolicy(policy *model.CachePolicy) error {
+	if policy.TTL < 0 || policy.StaleWhileRevalidate < 0 || policy.StaleIfError < 0 || policy.MaxEntrySize < 0 {
+		return errors.New("durations and sizes must not be negative")
+	}
+	for _, name := range policy.KeyHeaders {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("key headers must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/certstore"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// CertificateTypeCA CA证书包
+	CertificateTypeCA = "ca"
+	// CertificateTypeCert 带私钥的证书
+	CertificateTypeCert = "cert"
+)
+
+type CertificateController struct {
+	service      services.CertificateServiceImpl
+	store        *certstore.Store // 通过管理接口上传的证书
+	configStore  *certstore.Store // 配置目录中手动放置的证书
+	expiryWindow time.Duration    // 证书到期预警时间
+}
+
+func NewCertificateController(service services.CertificateServiceImpl, store, configStore *certstore.Store, expiryWindow time.Duration) *CertificateController {
+	return &CertificateController{
+		service:      service,
+		store:        store,
+		configStore:  configStore,
+		expiryWindow: expiryWindow,
+	}
+}
+
+// ExpiringCertificate 即将到期的证书
+type ExpiringCertificate struct {
+	Name     string
+	Source   string // upload为管理接口上传，config为配置目录
+	Subject  string
+	NotAfter time.Time
+	DaysLeft int
+}
+
+// 获取即将到期的证书，可通过days参数覆盖默认的预警天数
+func (ac *CertificateController) Expiring(c *gin.Context) {
+	window := ac.expiryWindow
+	if days := c.Query("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+			return
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	}
+
+	result := make([]ExpiringCertificate, 0)
+	sources := []struct {
+		name  string
+		store *certstore.Store
+	}{{"upload", ac.store}, {"config", ac.configStore}}
+	for _, source := range sources {
+		names, err := source.store.List()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, name := range names {
+			leaf, err := source.store.Leaf(name)
+			if err != nil {
+				continue
+			}
+			left := time.Until(leaf.NotAfter)
+			if left >= window {
+				continue
+			}
+			result = append(result, ExpiringCertificate{
+				Name:     name,
+				Source:   source.name,
+				Subject:  leaf.Subject.String(),
+				NotAfter: leaf.NotAfter,
+				DaysLeft: int(left.Hours() / 24),
+			})
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 上传证书
+func (ac *CertificateController) Create(c *gin.Context) {
+	var cert model.Certificate
+	if err := c.ShouldBindJSON(&cert); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 同名证书已存在时不能覆盖正在使用的证书文件
+	if _, err := ac.service.GetByName(context.Background(), cert.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "certificate already exists"})
+		return
+	}
+	staged, err := ac.stage(&cert)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ac.service.Add(context.Background(), &cert); err != nil {
+		staged.Discard()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := staged.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, cert)
+}
+
+// 获取所有证书信息
+func (ac *CertificateController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取证书信息
+func (ac *CertificateController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	cert, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cert)
+}
+
+// 更新证书（替换证书文件）
+func (ac *CertificateController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var cert model.Certificate
+	if err := c.ShouldBindJSON(&cert); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cert.Name = name
+	if _, err := ac.service.GetByName(context.Background(), name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	// 数据库更新成功后才替换证书文件
+	staged, err := ac.stage(&cert)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ac.service.UpdateByName(context.Background(), cert, name); err != nil {
+		staged.Discard()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := staged.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cert)
+}
+
+// 删除证书
+func (ac *CertificateController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ac.store.Remove(name); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// stage校验证书并写入临时文件，并根据证书内容填充元数据，调用方保存数据库后再提交文件
+func (ac *CertificateController) stage(cert *model.Certificate) (*certstore.Staged, error) {
+	switch cert.Type {
+	case CertificateTypeCA:
+		if cert.PrivateKey != "" {
+			return nil, errors.New("ca certificate must not carry a private key")
+		}
+	case CertificateTypeCert:
+		if cert.PrivateKey == "" {
+			return nil, errors.New("private key is required")
+		}
+	default:
+		return nil, errors.New("type must be ca or cert")
+	}
+
+	staged, err := ac.store.Stage(cert.Name, []byte(cert.PEM), []byte(cert.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	fillCertificateInfo(cert, staged.Certificates[0])
+	cert.PEM, cert.PrivateKey = "", ""
+	return staged, nil
+}
+
+// fillCertificateInfo根据证书填充证书元数据
+func fillCertificateInfo(cert *model.Certificate, leaf *x509.Certificate) {
+	cert.Subject = leaf.Subject.String()
+	cert.Issuer = leaf.Issuer.String()
+	cert.DNSNames = leaf.DNSNames
+	cert.NotBefore = leaf.NotBefore
+	cert.NotAfter = leaf.NotAfter
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsumerController struct {
+	service services.ConsumerServiceImpl
+}
+
+func NewConsumerController(service services.ConsumerServiceImpl) *ConsumerController {
+	return &ConsumerController{
+		service: service,
+	}
+}
+
+// 创建消费者信息，共享密钥只在创建时返回
+func (ac *ConsumerController) Create(c *gin.Context) {
+	var api model.Consumer
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有消费者信息
+func (ac *ConsumerController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	for _, api := range result {
+		api.MaskSecrets()
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取消费者信息
+func (ac *ConsumerController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	api.MaskSecrets()
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新消费者信息
+func (ac *ConsumerController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.Consumer
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 共享密钥为空或占位符时保留已保存的值
+	data.UnmaskSecrets()
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	data.MaskSecrets()
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除消费者信息
+func (ac *ConsumerController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/cors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CORSPolicyController struct {
+	service services.CORSPolicyServiceImpl
+}
+
+func NewCORSPolicyController(service services.CORSPolicyServiceImpl) *CORSPolicyController {
+	return &CORSPolicyController{
+		service: service,
+	}
+}
+
+// 创建跨域策略
+func (ac *CORSPolicyController) Create(c *gin.Context) {
+	var api model.CORSPolicy
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := cors.Compile(api.CORSOptions()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有跨域策略
+func (ac *CORSPolicyController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取跨域策略
+func (ac *CORSPolicyController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新跨域策略
+func (ac *CORSPolicyController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.CORSPolicy
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := cors.Compile(data.CORSOptions()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除跨域策略
+func (ac *CORSPolicyController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -2,10 +2,12 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"api-gateway/internal/model"
 	"api-gateway/internal/services"
+	"api-gateway/pkg/transform"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,11 +29,16 @@ func (ac *DownstreamController) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateDownstream(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	err := ac.service.Add(context.Background(), &api)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	api.MaskSecrets()
 	c.JSON(http.StatusCreated, api)
 }
 
@@ -42,6 +49,9 @@ func (ac *DownstreamController) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
 		return
 	}
+	for _, api := range result {
+		api.MaskSecrets()
+	}
 	c.JSON(http.StatusOK, result)
 }
 
@@ -53,6 +63,7 @@ func (ac *DownstreamController) GetByName(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	api.MaskSecrets()
 	c.JSON(http.StatusOK, api)
 }
 
@@ -64,12 +75,19 @@ func (ac *DownstreamController) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateDownstream(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 签名密钥只写，为空或占位符时保留已保存的值
+	data.UnmaskSecrets()
 
 	err := ac.service.UpdateByName(context.Background(), data, name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	data.MaskSecrets()
 	c.JSON(http.StatusOK, data)
 }
 
@@ -83,3 +101,14 @@ func (ac *DownstreamController) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// validateDownstream校验下游服务的请求头和响应头转换规则
+func validateDownstream(downstream *model.Downstream) error {
+	if _, err := transform.CompileHeaderRules(downstream.RequestHeaders); err != nil {
+		return fmt.Errorf("invalid request header rules: %v", err)
+	}
+	if _, err := transform.CompileHeaderRules(downstream.ResponseHeaders); err != nil {
+		return fmt.Errorf("invalid response header rules: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/ipacl"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IPAccessListController struct {
+	service services.IPAccessListServiceImpl
+}
+
+func NewIPAccessListController(service services.IPAccessListServiceImpl) *IPAccessListController {
+	return &IPAccessListController{
+		service: service,
+	}
+}
+
+// 创建访问控制列表
+func (ac *IPAccessListController) Create(c *gin.Context) {
+	var api model.IPAccessList
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateIPAccessList(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有访问控制列表
+func (ac *IPAccessListController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取访问控制列表
+func (ac *IPAccessListController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新访问控制列表
+func (ac *IPAccessListController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.IPAccessList
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateIPAccessList(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除访问控制列表
+func (ac *IPAccessListController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateIPAccessList校验访问控制列表中的IP和CIDR格式
+func validateIPAccessList(data *model.IPAccessList) error {
+	if _, err := ipacl.ParsePrefixes(data.Allow); err != nil {
+		return err
+	}
+	_, err := ipacl.ParsePrefixes(data.Deny)
+	return err
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWTIssuerController struct {
+	service services.JWTIssuerServiceImpl
+}
+
+func NewJWTIssuerController(service services.JWTIssuerServiceImpl) *JWTIssuerController {
+	return &JWTIssuerController{
+		service: service,
+	}
+}
+
+// 创建JWT签发者
+func (ac *JWTIssuerController) Create(c *gin.Context) {
+	var api model.JWTIssuer
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := jwt.Compile(api.JWTOptions()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	api.MaskSecrets()
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有JWT签发者
+func (ac *JWTIssuerController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	for _, api := range result {
+		api.MaskSecrets()
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取JWT签发者
+func (ac *JWTIssuerController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	api.MaskSecrets()
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新JWT签发者
+func (ac *JWTIssuerController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.JWTIssuer
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	existing, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	// 共享密钥为空或占位符时保留已保存的值，校验时使用已保存的密钥
+	data.UnmaskSecrets()
+	opts := data.JWTOptions()
+	if opts.Secret == "" {
+		opts.Secret = existing.Secret
+	}
+	if _, err := jwt.Compile(opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	data.MaskSecrets()
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除JWT签发者
+func (ac *JWTIssuerController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"api-gateway/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errNoUsagePlan = errors.New("consumer has no usage plan")
+
+type QuotaController struct {
+	service services.QuotaServiceImpl
+}
+
+func NewQuotaController(service services.QuotaServiceImpl) *QuotaController {
+	return &QuotaController{
+		service: service,
+	}
+}
+
+// 获取消费者当前周期的配额使用情况
+func (ac *QuotaController) GetByConsumer(c *gin.Context) {
+	status, err := ac.status(c)
+	if err != nil {
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// 重置消费者当前周期的配额使用量
+func (ac *QuotaController) Reset(c *gin.Context) {
+	status, err := ac.status(c)
+	if err != nil {
+		return
+	}
+	if err := ac.service.Reset(context.Background(), status); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	status.Requests, status.Bytes = 0, 0
+	status.RemainingRequests, status.RemainingBytes = status.RequestLimit, status.ByteLimit
+	c.JSON(http.StatusOK, status)
+}
+
+// status获取配额使用情况，出错时直接写入响应
+func (ac *QuotaController) status(c *gin.Context) (*services.QuotaStatus, error) {
+	consumer := c.Param("consumer")
+	status, err := ac.service.Status(context.Background(), consumer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, err
+	}
+	if status == nil {
+		err = errNoUsagePlan
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, err
+	}
+	return status, nil
+}
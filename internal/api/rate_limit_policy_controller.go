@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitPolicyController struct {
+	service services.RateLimitPolicyServiceImpl
+}
+
+func NewRateLimitPolicyController(service services.RateLimitPolicyServiceImpl) *RateLimitPolicyController {
+	return &RateLimitPolicyController{
+		service: service,
+	}
+}
+
+// 创建限流策略
+func (ac *RateLimitPolicyController) Create(c *gin.Context) {
+	var api model.RateLimitPolicy
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateRateLimitPolicy(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有限流策略
+func (ac *RateLimitPolicyController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取限流策略
+func (ac *RateLimitPolicyController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新限流策略
+func (ac *RateLimitPolicyController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.RateLimitPolicy
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateRateLimitPolicy(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除限流策略
+func (ac *RateLimitPolicyController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateRateLimitPolicy校验限流策略，算法为空时默认为令牌桶
+func validateRateLimitPolicy(policy *model.RateLimitPolicy) error {
+	if policy.Algorithm == "" {
+		policy.Algorithm = ratelimit.TokenBucket
+	}
+	if err := policy.Limit().Validate(); err != nil {
+		return err
+	}
+	for _, key := range policy.KeyBy {
+		switch {
+		case key == "ip", key == "consumer":
+		case strings.HasPrefix(key, "header:") && len(key) > len("header:"):
+		default:
+			return fmt.Errorf("invalid key %q, expected ip, consumer or header:<name>", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevocationController struct {
+	service services.RevokedCertificateServiceImpl
+}
+
+func NewRevocationController(service services.RevokedCertificateServiceImpl) *RevocationController {
+	return &RevocationController{
+		service: service,
+	}
+}
+
+// 吊销证书
+func (ac *RevocationController) Create(c *gin.Context) {
+	var data model.RevokedCertificate
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data.SerialNumber = NormalizeSerialNumber(data.SerialNumber)
+	if data.SerialNumber == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "serial number is required"})
+		return
+	}
+	err := ac.service.Add(context.Background(), &data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, data)
+}
+
+// 获取吊销列表
+func (ac *RevocationController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据序列号获取吊销信息
+func (ac *RevocationController) GetBySerialNumber(c *gin.Context) {
+	serial := NormalizeSerialNumber(c.Param("serial"))
+	data, err := ac.service.GetBySerialNumber(context.Background(), serial)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 撤销吊销
+func (ac *RevocationController) Delete(c *gin.Context) {
+	serial := NormalizeSerialNumber(c.Param("serial"))
+	err := ac.service.DeleteBySerialNumber(context.Background(), serial)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// NormalizeSerialNumber将证书序列号统一为不带分隔符和前导0的小写十六进制
+func NormalizeSerialNumber(serial string) string {
+	serial = strings.ToLower(strings.NewReplacer(":", "", " ", "", "-", "").Replace(serial))
+	serial = strings.TrimPrefix(serial, "0x")
+	serial = strings.TrimLeft(serial, "0")
+	return serial
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/soap"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SOAPBridgeController struct {
+	service services.SOAPBridgeServiceImpl
+}
+
+func NewSOAPBridgeController(service services.SOAPBridgeServiceImpl) *SOAPBridgeController {
+	return &SOAPBridgeController{
+		service: service,
+	}
+}
+
+// 创建SOAP桥接
+func (ac *SOAPBridgeController) Create(c *gin.Context) {
+	var api model.SOAPBridge
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateSOAPBridge(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有SOAP桥接
+func (ac *SOAPBridgeController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取SOAP桥接
+func (ac *SOAPBridgeController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新SOAP桥接
+func (ac *SOAPBridgeController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.SOAPBridge
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateSOAPBridge(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除SOAP桥接
+func (ac *SOAPBridgeController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateSOAPBridge校验SOAP桥接的版本、SOAPAction和模板
+func validateSOAPBridge(bridge *model.SOAPBridge) error {
+	_, err := soap.Compile(bridge.SOAPOptions())
+	return err
+}
+
+// soapDryRunRequest 试运行的输入，Response为模拟的下游SOAP响应，为空时只转换请求
+type soapDryRunRequest struct {
+	Body           json.RawMessage
+	Query          map[string]string
+	Path           map[string]string
+	Header         map[string]string
+	Response       string
+	ResponseStatus int // 模拟的下游响应状态码，为0时为200
+}
+
+// DryRun使用SOAP桥接转换示例请求和响应，不会请求下游服务
+func (ac *SOAPBridgeController) DryRun(c *gin.Context) {
+	name := c.Param("name")
+	data, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	var input soapDryRunRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bridge, err := soap.Compile(data.SOAPOptions())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body any
+	if len(input.Body) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(input.Body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	envelope, err := bridge.Request(soap.Data{Body: body, Query: input.Query, Path: input.Path, Header: input.Header})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	header := make(http.Header)
+	bridge.SetHeaders(header)
+	result := gin.H{"Request": gin.H{"Header": header, "Body": string(envelope)}}
+
+	if input.Response != "" {
+		status := input.ResponseStatus
+		if status == 0 {
+			status = http.StatusOK
+		}
+		status, converted := bridge.Response(status, []byte(input.Response))
+		result["Response"] = gin.H{"Status": status, "Body": json.RawMessage(converted)}
+	}
+	c.JSON(http.StatusOK, result)
+}
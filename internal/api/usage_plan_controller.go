@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/quota"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsagePlanController struct {
+	service services.UsagePlanServiceImpl
+}
+
+func NewUsagePlanController(service services.UsagePlanServiceImpl) *UsagePlanController {
+	return &UsagePlanController{
+		service: service,
+	}
+}
+
+// 创建使用计划
+func (ac *UsagePlanController) Create(c *gin.Context) {
+	var api model.UsagePlan
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateUsagePlan(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有使用计划
+func (ac *UsagePlanController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取使用计划
+func (ac *UsagePlanController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新使用计划
+func (ac *UsagePlanController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.UsagePlan
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateUsagePlan(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除使用计划
+func (ac *UsagePlanController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateUsagePlan校验使用计划的周期和时区
+func validateUsagePlan(plan *model.UsagePlan) error {
+	loc := time.UTC
+	if plan.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(plan.Timezone); err != nil {
+			return err
+		}
+	}
+	if plan.RequestLimit < 0 || plan.ByteLimit < 0 {
+		return errors.New("limits must not be negative")
+	}
+	_, err := quota.Current(plan.Period, loc, time.Now())
+	return err
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/waf"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WAFPolicyController struct {
+	service services.WAFPolicyServiceImpl
+}
+
+func NewWAFPolicyController(service services.WAFPolicyServiceImpl) *WAFPolicyController {
+	return &WAFPolicyController{
+		service: service,
+	}
+}
+
+// 创建WAF策略
+func (ac *WAFPolicyController) Create(c *gin.Context) {
+	var api model.WAFPolicy
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWAFPolicy(&api); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &api)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, api)
+}
+
+// 获取所有WAF策略
+func (ac *WAFPolicyController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 根据名称获取WAF策略
+func (ac *WAFPolicyController) GetByName(c *gin.Context) {
+	name := c.Param("name")
+	api, err := ac.service.GetByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, api)
+}
+
+// 更新WAF策略
+func (ac *WAFPolicyController) Update(c *gin.Context) {
+	name := c.Param("name")
+	var data model.WAFPolicy
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWAFPolicy(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ac.service.UpdateByName(context.Background(), data, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除WAF策略
+func (ac *WAFPolicyController) Delete(c *gin.Context) {
+	name := c.Param("name")
+	err := ac.service.DeleteByName(context.Background(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateWAFPolicy校验WAF策略，模式为空时默认为拦截
+func validateWAFPolicy(policy *model.WAFPolicy) error {
+	switch policy.Mode {
+	case "":
+		policy.Mode = waf.ModeBlock
+	case waf.ModeBlock, waf.ModeDetect:
+	default:
+		return errors.New("mode must be block or detect")
+	}
+	return nil
+}
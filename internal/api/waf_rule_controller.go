@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/waf"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WAFRuleController struct {
+	service services.WAFRuleServiceImpl
+}
+
+func NewWAFRuleController(service services.WAFRuleServiceImpl) *WAFRuleController {
+	return &WAFRuleController{
+		service: service,
+	}
+}
+
+// 创建自定义WAF规则
+func (ac *WAFRuleController) Create(c *gin.Context) {
+	var rule model.WAFRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWAFRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := ac.service.Add(context.Background(), &rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// 获取所有自定义WAF规则
+func (ac *WAFRuleController) List(c *gin.Context) {
+	result, err := ac.service.GetAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 获取内置WAF规则
+func (ac *WAFRuleController) Builtin(c *gin.Context) {
+	c.JSON(http.StatusOK, waf.Builtin())
+}
+
+// 根据规则ID获取自定义WAF规则
+func (ac *WAFRuleController) GetByRuleID(c *gin.Context) {
+	id := c.Param("id")
+	rule, err := ac.service.GetByRuleID(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// 更新自定义WAF规则
+func (ac *WAFRuleController) Update(c *gin.Context) {
+	id := c.Param("id")
+	var data model.WAFRule
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data.RuleID = id
+	if err := validateWAFRule(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 停用状态为布尔值，需要显式更新
+	err := ac.service.UpdateByRuleID(context.Background(), data, id)
+	if err == nil {
+		err = ac.service.SetDisabled(context.Background(), id, data.Disabled)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// 删除自定义WAF规则
+func (ac *WAFRuleController) Delete(c *gin.Context) {
+	id := c.Param("id")
+	err := ac.service.DeleteByRuleID(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateWAFRule校验自定义规则，规则ID不能与内置规则重复
+func validateWAFRule(rule *model.WAFRule) error {
+	if waf.IsBuiltin(rule.RuleID) {
+		return errors.New("rule id conflicts with a builtin rule")
+	}
+	_, err := waf.NewRule(rule.RuleID, rule.Category, rule.Targets, rule.Pattern, rule.Description)
+	return err
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/jsondiff"
+	"api-gateway/pkg/redact"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 审计记录中需要屏蔽的字段，管理接口返回的是解密后的值
+var auditRedaction = redact.Rules{
+	JSONPaths: []string{"**.Secret", "**.SecretKey", "**.SessionToken", "**.PrivateKey"},
+}
+
+type AuditMiddleware struct {
+	AuditService services.AuditLogServiceImpl
+	Handler      http.Handler // 管理接口路由，用于读取资源变更前后的状态
+	BasePath     string       // 管理接口的路径前缀，如/api/v1
+	ActorHeader  string       // 标识操作人的请求头
+
+	redactor *redact.Redactor
+}
+
+func NewAuditMiddleware(service services.AuditLogServiceImpl, handler http.Handler, basePath, actorHeader string) *AuditMiddleware {
+	redactor, _ := redact.Compile(redact.Defaults, auditRedaction)
+	return &AuditMiddleware{
+		AuditService: service,
+		Handler:      handler,
+		BasePath:     strings.TrimRight(basePath, "/"),
+		ActorHeader:  actorHeader,
+		redactor:     redactor,
+	}
+}
+
+// auditWriter 记录响应体，用于获取新建资源的内容
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Audit记录所有成功的变更操作，通过读取资源的GET接口获取变更前后的内容，新增的管理资源无需额外处理
+func (am *AuditMiddleware) Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		resource, name, action, resourcePath := am.describe(c)
+		var before []byte
+		if resourcePath != "" {
+			before = am.fetch(c.Request.Context(), resourcePath)
+		}
+
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status < 200 || status >= 300 {
+			return
+		}
+
+		var after []byte
+		switch {
+		case action == "delete":
+		case resourcePath != "":
+			after = am.fetch(c.Request.Context(), resourcePath)
+		default:
+			after = writer.body.Bytes()
+			if name == "" {
+				name = createdName(after)
+			}
+		}
+
+		entry := &model.AuditLog{
+			Actor:    am.actor(c),
+			SourceIP: c.ClientIP(),
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Resource: resource,
+			Name:     name,
+			Action:   action,
+			Status:   status,
+			Before:   string(am.redact(before)),
+			After:    string(am.redact(after)),
+		}
+		entry.Diff, _ = jsondiff.Diff([]byte(entry.Before), []byte(entry.After))
+		if err := am.AuditService.Add(context.Background(), entry); err != nil {
+			global.Logger.Error("写入审计记录失败", zap.String("path", entry.Path), zap.Error(err))
+		}
+	}
+}
+
+// describe根据路由获取资源类型、名称、操作和资源地址
+// 如/api/v1/apis/:name的资源类型为apis，/api/v1/quotas/:name/reset的操作为reset
+func (am *AuditMiddleware) describe(c *gin.Context) (resource, name, action, resourcePath string) {
+	route := strings.Split(strings.Trim(strings.TrimPrefix(c.FullPath(), am.BasePath), "/"), "/")
+	path := strings.Split(strings.Trim(strings.TrimPrefix(c.Request.URL.Path, am.BasePath), "/"), "/")
+
+	paramIndex := -1
+	for i, seg := range route {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			paramIndex = i
+			break
+		}
+	}
+	if paramIndex < 0 {
+		action = "create"
+		if c.Request.Method != http.MethodPost {
+			action = strings.ToLower(c.Request.Method)
+		}
+		return strings.Join(route, "/"), "", action, ""
+	}
+
+	resource = strings.Join(route[:paramIndex], "/")
+	name = c.Param(strings.TrimLeft(route[paramIndex], ":*"))
+	if len(path) > paramIndex {
+		resourcePath = am.BasePath + "/" + strings.Join(path[:paramIndex+1], "/")
+	}
+	switch {
+	case paramIndex < len(route)-1:
+		action = strings.Join(route[paramIndex+1:], "/")
+	case c.Request.Method == http.MethodDelete:
+		action = "delete"
+	default:
+		action = "update"
+	}
+	return resource, name, action, resourcePath
+}
+
+// fetch通过管理接口读取资源当前的内容，资源不存在时返回nil
+func (am *AuditMiddleware) fetch(ctx context.Context, path string) []byte {
+	if am.Handler == nil {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil
+	}
+	w := &bufferWriter{header: make(http.Header)}
+	am.Handler.ServeHTTP(w, req)
+	if w.status != http.StatusOK {
+		return nil
+	}
+	return w.body.Bytes()
+}
+
+// actor获取操作人，依次使用配置的请求头和Basic认证的用户名
+func (am *AuditMiddleware) actor(c *gin.Context) string {
+	if am.ActorHeader != "" {
+		if actor := c.GetHeader(am.ActorHeader); actor != "" {
+			return actor
+		}
+	}
+	if user, _, ok := c.Request.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+func (am *AuditMiddleware) redact(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return am.redactor.Body("application/json", data)
+}
+
+// createdName从新建资源的响应中获取资源名称
+func createdName(body []byte) string {
+	var v struct {
+		Name         string
+		RuleID       string
+		SerialNumber string
+	}
+	if json.Unmarshal(body, &v) != nil {
+		return ""
+	}
+	for _, name := range []string{v.Name, v.RuleID, v.SerialNumber} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// bufferWriter 内部请求使用的响应缓冲
+type bufferWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferWriter) Header() http.Header { return w.header }
+
+func (w *bufferWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/certstore"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// AuthModeMTLS 客户端证书认证
+	AuthModeMTLS = "mtls"
+
+	// 转发给下游的客户端证书身份请求头
+	HeaderClientIdentity    = "X-Client-Identity"
+	HeaderClientSubject     = "X-Client-Cert-Subject"
+	HeaderClientSerial      = "X-Client-Cert-Serial"
+	HeaderClientFingerprint = "X-Client-Cert-Fingerprint"
+)
+
+type ClientCertAuthMiddleware struct {
+	ConsumerService   services.ConsumerServiceImpl
+	RevocationService services.RevokedCertificateServiceImpl
+	CertStore         *certstore.Store
+}
+
+func NewClientCertAuthMiddleware(consumerService services.ConsumerServiceImpl, revocationService services.RevokedCertificateServiceImpl, store *certstore.Store) *ClientCertAuthMiddleware {
+	return &ClientCertAuthMiddleware{
+		ConsumerService:   consumerService,
+		RevocationService: revocationService,
+		CertStore:         store,
+	}
+}
+
+// ClientCertAuth对启用了客户端证书认证的路由校验客户端证书，并将证书身份通过请求头转发给下游
+func (cm *ClientCertAuthMiddleware) ClientCertAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 身份请求头只能由网关设置，先移除客户端传入的值
+		for _, h := range []string{HeaderClientIdentity, HeaderClientSubject, HeaderClientSerial, HeaderClientFingerprint} {
+			c.Request.Header.Del(h)
+		}
+
+		route := GetRoute(c)
+		if route == nil || route.AuthMode != AuthModeMTLS {
+			c.Next()
+			return
+		}
+
+		leaf, err := cm.verify(c, route.ClientCA)
+		if err != nil {
+			global.Logger.Warn("客户端证书认证失败",
+				zap.String("api", route.Name),
+				zap.String("client_ip", c.ClientIP()),
+				zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		consumer, err := cm.ConsumerService.MatchCertificate(context.Background(), leaf)
+		if err != nil {
+			global.Logger.Warn("客户端证书未映射到消费者",
+				zap.String("api", route.Name),
+				zap.String("subject", leaf.Subject.String()))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "client certificate is not mapped to a consumer"})
+			return
+		}
+
+		fingerprint := sha256.Sum256(leaf.Raw)
+		c.Request.Header.Set(HeaderClientIdentity, consumer.Name)
+		c.Request.Header.Set(HeaderClientSubject, leaf.Subject.String())
+		c.Request.Header.Set(HeaderClientSerial, leaf.SerialNumber.Text(16))
+		c.Request.Header.Set(HeaderClientFingerprint, hex.EncodeToString(fingerprint[:]))
+		c.Set(ConsumerKey, consumer.Name)
+		c.Next()
+	}
+}
+
+// verify校验客户端证书链和吊销状态，返回客户端证书
+func (cm *ClientCertAuthMiddleware) verify(c *gin.Context, caName string) (*x509.Certificate, error) {
+	state := c.Request.TLS
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil, errors.New("client certificate required")
+	}
+	leaf := state.PeerCertificates[0]
+
+	if caName != "" {
+		pool, err := cm.CertStore.CertPool(caName)
+		if err != nil {
+			return nil, err
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err = leaf.Verify(x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		if err != nil {
+			return nil, errors.New("client certificate not trusted")
+		}
+	} else if len(state.VerifiedChains) == 0 {
+		// 路由未指定CA时，依赖监听器配置的CA完成校验
+		return nil, errors.New("client certificate not verified")
+	}
+
+	serial := strings.ToLower(leaf.SerialNumber.Text(16))
+	revoked, err := cm.RevocationService.IsRevoked(context.Background(), serial)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("client certificate revoked")
+	}
+	return leaf, nil
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/pkg/compress"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CompressionOptions 响应压缩和请求解压配置
+type CompressionOptions struct {
+	Enabled        bool     // 是否压缩响应
+	Encodings      []string // 支持的编码及优先顺序
+	MinSize        int      // 响应体达到该大小（字节）才压缩
+	Level          int      // 压缩级别，0为各编码的默认级别
+	ContentTypes   []string // 压缩的内容类型，支持path.Match通配符，如text/*
+	MaxDecodedSize int64    // 解压请求体后的最大大小（字节），0表示不限制
+}
+
+type CompressionMiddleware struct {
+	opts CompressionOptions
+}
+
+func NewCompressionMiddleware(opts CompressionOptions) *CompressionMiddleware {
+	return &CompressionMiddleware{opts: opts}
+}
+
+// Compression为开启的路由解压请求体，并按Accept-Encoding压缩响应
+// 该中间件需要在流量统计之后注册，流量统计才能得到实际传输的字节数
+func (cm *CompressionMiddleware) Compression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if route := GetRoute(c); route != nil && route.DecompressRequest {
+			if status, err := cm.decompressRequest(c.Request); err != nil {
+				c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if !cm.opts.Enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		encoding := compress.Negotiate(c.GetHeader("Accept-Encoding"), cm.opts.Encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+		writer := &compressWriter{ResponseWriter: c.Writer, opts: &cm.opts, encoding: encoding}
+		c.Writer = writer
+
+		c.Next()
+
+		if err := writer.finish(); err != nil {
+			global.Logger.Error("压缩响应失败", zap.String("encoding", encoding), zap.Error(err))
+		}
+	}
+}
+
+// decompressRequest解压请求体，下游收到的是未压缩的请求
+func (cm *CompressionMiddleware) decompressRequest(req *http.Request) (int, error) {
+	encoding := req.Header.Get("Content-Encoding")
+	if encoding == "" || req.Body == nil || req.Body == http.NoBody {
+		return 0, nil
+	}
+	if !compress.Supported(encoding) {
+		return http.StatusUnsupportedMediaType, errors.New("unsupported content encoding")
+	}
+	reader, err := compress.NewReader(encoding, req.Body)
+	if err != nil {
+		return http.StatusBadRequest, errors.New("malformed compressed body")
+	}
+	defer reader.Close()
+	body, err := compress.ReadAll(reader, cm.opts.MaxDecodedSize)
+	if errors.Is(err, compress.ErrTooLarge) {
+		return http.StatusRequestEntityTooLarge, err
+	}
+	if err != nil {
+		return http.StatusBadRequest, errors.New("malformed compressed body")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Del("Content-Encoding")
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return 0, nil
+}
+
+// compressWriter 压缩响应的ResponseWriter
+// 响应体先缓冲到MinSize，不足MinSize的响应不压缩
+type compressWriter struct {
+	gin.ResponseWriter
+	opts     *CompressionOptions
+	encoding string
+
+	checked  bool // 是否已经检查过响应是否可以压缩
+	eligible bool
+	decided  bool
+	encoder  io.WriteCloser // 为nil时不压缩
+	buf      bytes.Buffer
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		return w.write(b)
+	}
+	w.buf.Write(b)
+	if w.check() && w.buf.Len() < w.opts.MinSize {
+		return len(b), nil
+	}
+	if err := w.decide(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow延迟到决定是否压缩后再写出响应头
+func (w *compressWriter) WriteHeaderNow() {}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish写出剩余的缓冲并结束压缩
+func (w *compressWriter) finish() error {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
+
+func (w *compressWriter) write(b []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide根据响应头和缓冲的大小决定是否压缩，并写出响应头和缓冲的内容
+func (w *compressWriter) decide() error {
+	w.decided = true
+	if w.check() {
+		h := w.Header()
+		h.Add("Vary", "Accept-Encoding")
+		if w.buf.Len() >= w.opts.MinSize {
+			encoder, err := compress.NewWriter(w.encoding, w.ResponseWriter, w.opts.Level)
+			if err != nil {
+				return err
+			}
+			w.encoder = encoder
+			h.Del("Content-Length")
+			h.Set("Content-Encoding", w.encoding)
+			// 压缩后的内容与原内容不再逐字节相同，强校验器需要改为弱校验器
+			if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				h.Set("ETag", "W/"+etag)
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	_, err := w.write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// check判断响应是否可以压缩，结果在第一次调用时确定
+func (w *compressWriter) check() bool {
+	if w.checked {
+		return w.eligible
+	}
+	w.checked = true
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || strings.Contains(strings.ToLower(h.Get("Cache-Control")), "no-transform") {
+		return false
+	}
+	if length, err := strconv.Atoi(h.Get("Content-Length")); err == nil && length < w.opts.MinSize {
+		return false
+	}
+	w.eligible = matchContentType(w.opts.ContentTypes, h.Get("Content-Type"))
+	return w.eligible
+}
+
+// matchContentType判断内容类型是否在压缩的类型中
+func matchContentType(patterns []string, contentType string) bool {
+	media, _, _ := strings.Cut(contentType, ";")
+	media = strings.ToLower(strings.TrimSpace(media))
+	if media == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), media); ok {
+			return true
+		}
+	}
+	return false
+}
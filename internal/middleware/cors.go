@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/cors"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CORSMiddleware struct {
+	CORSPolicyService services.CORSPolicyServiceImpl
+
+	mu       sync.Mutex
+	compiled map[string]compiledCORSPolicy
+}
+
+// compiledCORSPolicy 编译后的策略及其更新时间，策略更新后重新编译
+type compiledCORSPolicy struct {
+	updatedAt time.Time
+	policy    *cors.Policy
+}
+
+func NewCORSMiddleware(service services.CORSPolicyServiceImpl) *CORSMiddleware {
+	return &CORSMiddleware{
+		CORSPolicyService: service,
+		compiled:          make(map[string]compiledCORSPolicy),
+	}
+}
+
+// CORS根据路由引用的跨域策略处理跨域请求，预检请求直接由网关响应，不转发到下游
+func (cm *CORSMiddleware) CORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		if route == nil || route.CORSPolicy == "" || c.Request.Header.Get("Origin") == "" {
+			c.Next()
+			return
+		}
+
+		policy, err := cm.policy(route.CORSPolicy)
+		if err != nil {
+			global.Logger.Error("加载跨域策略失败", zap.String("policy", route.CORSPolicy), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cors policy"})
+			return
+		}
+
+		if cors.IsPreflight(c.Request) {
+			if !policy.Preflight(c.Request, c.Writer.Header()) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Writer = &corsWriter{ResponseWriter: c.Writer, policy: policy, request: c.Request}
+		c.Next()
+	}
+}
+
+// policy获取编译后的跨域策略
+func (cm *CORSMiddleware) policy(name string) (*cors.Policy, error) {
+	data, err := cm.CORSPolicyService.GetByName(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cached, ok := cm.compiled[name]; ok && cached.updatedAt.Equal(data.UpdatedAt) {
+		return cached.policy, nil
+	}
+	policy, err := cors.Compile(data.CORSOptions())
+	if err != nil {
+		return nil, err
+	}
+	cm.compiled[name] = compiledCORSPolicy{updatedAt: data.UpdatedAt, policy: policy}
+	return policy, nil
+}
+
+// corsWriter在写出响应头前移除下游返回的跨域响应头，统一使用网关的跨域策略
+type corsWriter struct {
+	gin.ResponseWriter
+	policy  *cors.Policy
+	request *http.Request
+	applied bool
+}
+
+func (w *corsWriter) apply() {
+	if w.applied {
+		return
+	}
+	w.applied = true
+	h := w.ResponseWriter.Header()
+	for name := range h {
+		if strings.HasPrefix(name, "Access-Control-") {
+			h.Del(name)
+		}
+	}
+	w.policy.Actual(w.request, h)
+}
+
+func (w *corsWriter) WriteHeader(code int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *corsWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *corsWriter) Write(b []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *corsWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
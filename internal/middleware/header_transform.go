@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/transform"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RequestIDKey 上下文中保存的请求ID
+const RequestIDKey = "gateway_request_id"
+
+type HeaderTransformMiddleware struct {
+	DownstreamService services.DownstreamServiceImpl
+
+	mu       sync.Mutex
+	compiled map[string]compiledHeaderRules
+}
+
+// compiledHeaderRules 路由或下游编译后的转换规则及其更新时间，更新后重新编译
+type compiledHeaderRules struct {
+	updatedAt time.Time
+	request   transform.HeaderRules
+	response  transform.HeaderRules
+}
+
+func NewHeaderTransformMiddleware(service services.DownstreamServiceImpl) *HeaderTransformMiddleware {
+	return &HeaderTransformMiddleware{
+		DownstreamService: service,
+		compiled:          make(map[string]compiledHeaderRules),
+	}
+}
+
+// HeaderTransform按顺序执行下游和路由的请求头、响应头转换规则
+// 该中间件需要最后注册，请求头规则只影响转发到下游的请求，响应头规则只影响下游的响应
+func (hm *HeaderTransformMiddleware) HeaderTransform() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		if route == nil {
+			c.Next()
+			return
+		}
+		var rules []compiledHeaderRules
+		if downstream, err := hm.DownstreamService.GetByName(context.Background(), route.Downstream); err == nil {
+			rules = append(rules, hm.rules("downstream:"+downstream.Name, downstream.UpdatedAt, downstream.RequestHeaders, downstream.ResponseHeaders))
+		}
+		rules = append(rules, hm.rules("api:"+route.Name, route.UpdatedAt, route.RequestHeaders, route.ResponseHeaders))
+
+		vars := NewRequestVars(c)
+		// 响应头规则中的变量取自客户端的原始请求
+		original := c.Request.Clone(c.Request.Context())
+		for _, r := range rules {
+			r.request.Apply(c.Request.Header, vars)
+		}
+		vars.Request = original
+
+		writer := &headerTransformWriter{ResponseWriter: c.Writer, rules: rules, vars: vars}
+		c.Writer = writer
+		c.Next()
+		writer.apply()
+	}
+}
+
+// rules获取编译后的转换规则，规则在保存时已经校验过，编译失败时记录日志并忽略
+func (hm *HeaderTransformMiddleware) rules(key string, updatedAt time.Time, request, response []transform.HeaderRule) compiledHeaderRules {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	if cached, ok := hm.compiled[key]; ok && cached.updatedAt.Equal(updatedAt) {
+		return cached
+	}
+	compiled := compiledHeaderRules{updatedAt: updatedAt}
+	var err error
+	if compiled.request, err = transform.CompileHeaderRules(request); err != nil {
+		global.Logger.Error("编译请求头转换规则失败", zap.String("key", key), zap.Error(err))
+	}
+	if compiled.response, err = transform.CompileHeaderRules(response); err != nil {
+		global.Logger.Error("编译响应头转换规则失败", zap.String("key", key), zap.Error(err))
+	}
+	hm.compiled[key] = compiled
+	return compiled
+}
+
+// NewRequestVars返回请求的模板变量
+func NewRequestVars(c *gin.Context) *transform.RequestVars {
+	vars := &transform.RequestVars{
+		Request:    c.Request,
+		ClientIP:   c.ClientIP(),
+		RequestID:  RequestID(c),
+		Consumer:   GetConsumer(c),
+		PathParams: GetPathParams(c),
+		Now:        time.Now(),
+		Claims:     GetJWTClaims(c),
+	}
+	if route := GetRoute(c); route != nil {
+		vars.Route = route.Name
+	}
+	return vars
+}
+
+// RequestID返回请求ID，优先使用客户端的X-Request-Id，没有时生成一个
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	id := c.GetHeader("X-Request-Id")
+	if id == "" || len(id) > 128 {
+		id = uuid.NewString()
+	}
+	c.Set(RequestIDKey, id)
+	return id
+}
+
+// headerTransformWriter 在写出响应头之前执行响应头转换规则
+type headerTransformWriter struct {
+	gin.ResponseWriter
+	rules   []compiledHeaderRules
+	vars    *transform.RequestVars
+	applied bool
+}
+
+func (w *headerTransformWriter) apply() {
+	if w.applied {
+		return
+	}
+	w.applied = true
+	h := w.ResponseWriter.Header()
+	for _, r := range w.rules {
+		r.response.Apply(h, w.vars)
+	}
+}
+
+func (w *headerTransformWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *headerTransformWriter) Write(b []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *headerTransformWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *headerTransformWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
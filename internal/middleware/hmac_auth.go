@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/signature"
+	"api-gateway/utils"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// AuthModeHMAC HMAC请求签名认证
+	AuthModeHMAC = "hmac"
+	// 默认允许的签名时间偏差
+	defaultClockSkew = 5 * time.Minute
+	// 随机数缓存在pebble中的键前缀
+	noncePrefix = "nonce_"
+)
+
+type HMACAuthMiddleware struct {
+	ConsumerService services.ConsumerServiceImpl
+	Nonces          *NonceCache
+}
+
+func NewHMACAuthMiddleware(consumerService services.ConsumerServiceImpl, db *pebble.DB) *HMACAuthMiddleware {
+	return &HMACAuthMiddleware{
+		ConsumerService: consumerService,
+		Nonces:          NewNonceCache(db),
+	}
+}
+
+// HMACAuth对启用了HMAC认证的路由校验请求签名，校验通过后将消费者名称保存到上下文中
+func (hm *HMACAuthMiddleware) HMACAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		if route == nil || route.AuthMode != AuthModeHMAC {
+			c.Next()
+			return
+		}
+
+		consumer, err := hm.verify(c, time.Duration(route.ClockSkew)*time.Second)
+		if err != nil {
+			global.Logger.Warn("HMAC认证失败",
+				zap.String("api", route.Name),
+				zap.String("client_ip", c.ClientIP()),
+				zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(ConsumerKey, consumer)
+		c.Next()
+	}
+}
+
+// verify校验请求签名，返回消费者名称
+func (hm *HMACAuthMiddleware) verify(c *gin.Context, skew time.Duration) (string, error) {
+	if skew <= 0 {
+		skew = defaultClockSkew
+	}
+	req := c.Request
+	auth, err := signature.ParseAuthorization(req.Header.Get("Authorization"))
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := req.Header.Get(signature.HeaderTimestamp)
+	nonce := req.Header.Get(signature.HeaderNonce)
+	if timestamp == "" || nonce == "" {
+		return "", errors.New("missing signature timestamp or nonce")
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", errors.New("invalid signature timestamp")
+	}
+	signedAt := time.Unix(ts, 0)
+	if d := time.Since(signedAt); d > skew || d < -skew {
+		return "", errors.New("signature timestamp outside allowed clock skew")
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewBuffer(body)) // 重置请求体，以便后续转发
+	}
+	bodyHash := signature.HashBody(body)
+	if digest := req.Header.Get(signature.HeaderContentSHA256); digest != "" && digest != bodyHash {
+		return "", errors.New("body digest mismatch")
+	}
+
+	consumer, err := hm.ConsumerService.GetByName(context.Background(), auth.Credential)
+	if err != nil {
+		return "", errors.New("unknown credential")
+	}
+
+	canonical := signature.CanonicalRequest(req, auth.SignedHeaders, timestamp, nonce, bodyHash)
+	if !signature.Equal(signature.Sum(consumer.Secret, canonical), auth.Signature) {
+		return "", errors.New("signature mismatch")
+	}
+
+	// 签名校验通过后再记录随机数，避免伪造请求占用随机数
+	// 随机数在时间窗口两侧都需要保留，过期时间取签名时间加上允许偏差
+	if err := hm.Nonces.Use(consumer.Name, nonce, signedAt.Add(skew)); err != nil {
+		return "", err
+	}
+	return consumer.Name, nil
+}
+
+// NonceCache 基于pebble的随机数缓存，用于防止签名请求被重放
+type NonceCache struct {
+	db *pebble.DB
+	mu sync.Mutex
+}
+
+func NewNonceCache(db *pebble.DB) *NonceCache {
+	nc := &NonceCache{db: db}
+	go nc.purgeLoop(time.Minute)
+	return nc
+}
+
+// Use记录随机数，随机数在过期前已被使用过时返回错误
+func (nc *NonceCache) Use(consumer, nonce string, expireAt time.Time) error {
+	key := []byte(noncePrefix + consumer + "_" + nonce)
+
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	value, closer, err := nc.db.Get(key)
+	if err == nil {
+		expired := int64(utils.Btoi(value)) < time.Now().Unix()
+		closer.Close()
+		if !expired {
+			return errors.New("replayed nonce")
+		}
+	} else if !errors.Is(err, pebble.ErrNotFound) {
+		return err
+	}
+	return nc.db.Set(key, utils.Itob(int(expireAt.Unix())), pebble.Sync)
+}
+
+// purgeLoop定期清理过期的随机数
+func (nc *NonceCache) purgeLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := nc.purge(); err != nil {
+			global.Logger.Error("清理过期随机数失败", zap.Error(err))
+		}
+	}
+}
+
+func (nc *NonceCache) purge() error {
+	iter, err := nc.db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(noncePrefix),
+		UpperBound: utils.PrefixUpperBound([]byte(noncePrefix)),
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	now := time.Now().Unix()
+	batch := nc.db.NewBatch()
+	defer batch.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		if int64(utils.Btoi(iter.Value())) < now {
+			batch.Delete(append([]byte(nil), iter.Key()...), nil)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.NoSync)
+}
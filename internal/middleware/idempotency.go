@@ -0,0 +1,279 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/secret"
+	"api-gateway/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// 幂等记录在pebble中的键前缀
+	idempotencyPrefix = "idem_"
+	// 幂等键的最大长度
+	maxIdempotencyKeyLength = 255
+	// 处理中的记录在该时间后视为已放弃，网关在转发过程中退出时客户端可以重试
+	idempotencyLockTimeout = time.Minute
+)
+
+// idempotencyRecord 幂等键对应的请求指纹和响应
+type idempotencyRecord struct {
+	Fingerprint string
+	Pending     bool
+	ExpireAt    time.Time
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+type IdempotencyMiddleware struct {
+	db *pebble.DB
+	mu sync.Mutex
+}
+
+func NewIdempotencyMiddleware(db *pebble.DB) *IdempotencyMiddleware {
+	im := &IdempotencyMiddleware{db: db}
+	go im.purgeLoop(10 * time.Minute)
+	return im
+}
+
+// Idempotency为开启幂等的路由处理携带Idempotency-Key的POST和PATCH请求
+// 同一路由、消费者和幂等键的后续请求直接返回第一次的响应
+func (im *IdempotencyMiddleware) Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		method := c.Request.Method
+		if route == nil || route.IdempotencyTTL <= 0 || (method != http.MethodPost && method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+		idemKey := c.GetHeader("Idempotency-Key")
+		if idemKey == "" {
+			c.Next()
+			return
+		}
+		if len(idemKey) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := idempotencyKey(route.Name, GetConsumer(c), idemKey)
+		fingerprint := requestFingerprint(c.Request, body)
+		record, err := im.acquire(key, fingerprint)
+		if err != nil {
+			global.Logger.Error("读取幂等记录失败", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Idempotency store unavailable"})
+			return
+		}
+		if record != nil {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key reused with a different request"})
+			case record.Pending:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is in progress"})
+			default:
+				replay(c, record)
+			}
+			return
+		}
+
+		// 之前的中间件设置的响应头（如限流）每次请求都会重新计算，不保存到记录中
+		before := make(map[string]bool)
+		for name := range c.Writer.Header() {
+			before[name] = true
+		}
+		writer := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		status := writer.Status()
+		// 下游或网关出错时删除记录，允许客户端使用同一个幂等键重试
+		if status >= http.StatusInternalServerError {
+			if err := im.db.Delete(key, pebble.Sync); err != nil {
+				global.Logger.Error("删除幂等记录失败", zap.Error(err))
+			}
+			return
+		}
+		header := make(http.Header)
+		for name, values := range c.Writer.Header() {
+			if !before[name] {
+				header[name] = values
+			}
+		}
+		err = im.save(key, &idempotencyRecord{
+			Fingerprint: fingerprint,
+			ExpireAt:    time.Now().Add(time.Duration(route.IdempotencyTTL) * time.Second),
+			Status:      status,
+			Header:      header,
+			Body:        writer.body.Bytes(),
+		})
+		if err != nil {
+			global.Logger.Error("保存幂等记录失败", zap.Error(err))
+		}
+	}
+}
+
+// acquire查找未过期的记录，不存在时写入处理中的记录并返回nil，表示当前请求可以转发
+func (im *IdempotencyMiddleware) acquire(key []byte, fingerprint string) (*idempotencyRecord, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	record, err := im.load(key)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && time.Now().Before(record.ExpireAt) {
+		return record, nil
+	}
+	return nil, im.save(key, &idempotencyRecord{
+		Fingerprint: fingerprint,
+		Pending:     true,
+		ExpireAt:    time.Now().Add(idempotencyLockTimeout),
+	})
+}
+
+func (im *IdempotencyMiddleware) load(key []byte) (*idempotencyRecord, error) {
+	value, closer, err := im.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return decodeIdempotencyRecord(value)
+}
+
+// save加密保存记录，记录中包含下游的响应体
+func (im *IdempotencyMiddleware) save(key []byte, record *idempotencyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	sealed, err := secret.Seal(data)
+	if err != nil {
+		return err
+	}
+	return im.db.Set(key, sealed, pebble.Sync)
+}
+
+// purgeLoop定期清理过期的幂等记录
+func (im *IdempotencyMiddleware) purgeLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := im.purge(); err != nil {
+			global.Logger.Error("清理过期幂等记录失败", zap.Error(err))
+		}
+	}
+}
+
+func (im *IdempotencyMiddleware) purge() error {
+	iter, err := im.db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(idempotencyPrefix),
+		UpperBound: utils.PrefixUpperBound([]byte(idempotencyPrefix)),
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	now := time.Now()
+	batch := im.db.NewBatch()
+	defer batch.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		record, err := decodeIdempotencyRecord(iter.Value())
+		if err != nil || !now.Before(record.ExpireAt) {
+			batch.Delete(append([]byte(nil), iter.Key()...), nil)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.NoSync)
+}
+
+func decodeIdempotencyRecord(value []byte) (*idempotencyRecord, error) {
+	data, err := secret.Open(value)
+	if err != nil {
+		return nil, err
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// replay返回保存的响应
+// 保存的是压缩后的响应，客户端不接受该编码时解码后返回
+func replay(c *gin.Context, record *idempotencyRecord) {
+	body := record.Body
+	header := record.Header.Clone()
+	if encoding := header.Get("Content-Encoding"); encoding != "" && compress.Negotiate(c.GetHeader("Accept-Encoding"), []string{encoding}) == "" {
+		decoded, err := compress.Decode(encoding, body, 0)
+		if err == nil {
+			body = decoded
+			header.Del("Content-Encoding")
+		}
+	}
+	h := c.Writer.Header()
+	for name, values := range header {
+		h[name] = values
+	}
+	h.Set("Idempotent-Replayed", "true")
+	c.Writer.WriteHeader(record.Status)
+	c.Writer.Write(body)
+	c.Abort()
+}
+
+// idempotencyKey生成记录的键，幂等键由客户端提供，哈希后再作为pebble的键
+func idempotencyKey(route, consumer, key string) []byte {
+	sum := sha256.Sum256([]byte(route + "\x00" + consumer + "\x00" + key))
+	return []byte(idempotencyPrefix + hex.EncodeToString(sum[:]))
+}
+
+// requestFingerprint计算请求的指纹，用于判断重复使用的幂等键是否对应同一个请求
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// captureWriter 在写出响应的同时保存响应体
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/jwt"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// AuthModeJWT JWT Bearer令牌认证
+	AuthModeJWT = "jwt"
+	// JWTClaimsKey 校验通过的JWT声明在上下文中的键
+	JWTClaimsKey = "jwt_claims"
+)
+
+type JWTAuthMiddleware struct {
+	JWTIssuerService services.JWTIssuerServiceImpl
+	ConsumerService  services.ConsumerServiceImpl
+
+	mu       sync.Mutex
+	compiled map[string]compiledJWTIssuer
+}
+
+// compiledJWTIssuer 编译后的校验器及签发者的更新时间，签发者更新后重新编译
+type compiledJWTIssuer struct {
+	updatedAt     time.Time
+	verifier      *jwt.Verifier
+	consumerClaim string
+}
+
+func NewJWTAuthMiddleware(issuerService services.JWTIssuerServiceImpl, consumerService services.ConsumerServiceImpl) *JWTAuthMiddleware {
+	return &JWTAuthMiddleware{
+		JWTIssuerService: issuerService,
+		ConsumerService:  consumerService,
+		compiled:         make(map[string]compiledJWTIssuer),
+	}
+}
+
+// JWTAuth对启用了JWT认证的路由校验Bearer令牌，校验通过后将声明保存到上下文中，
+// 只有校验通过的声明才能在模板中通过jwt.*使用
+func (jm *JWTAuthMiddleware) JWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		if route == nil || route.AuthMode != AuthModeJWT {
+			c.Next()
+			return
+		}
+
+		issuer, err := jm.issuer(route.JWTIssuer)
+		if err != nil {
+			global.Logger.Error("加载JWT签发者失败", zap.String("issuer", route.JWTIssuer), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load jwt issuer"})
+			return
+		}
+
+		claims, err := jm.verify(c, issuer)
+		if err != nil {
+			global.Logger.Warn("JWT认证失败",
+				zap.String("api", route.Name),
+				zap.String("client_ip", c.ClientIP()),
+				zap.Error(err))
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		if issuer.consumerClaim != "" {
+			name, _ := claims[issuer.consumerClaim].(string)
+			consumer, err := jm.ConsumerService.GetByName(context.Background(), name)
+			if name == "" || err != nil {
+				global.Logger.Warn("JWT令牌未映射到消费者",
+					zap.String("api", route.Name),
+					zap.String("claim", issuer.consumerClaim),
+					zap.String("value", name))
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token is not mapped to a consumer"})
+				return
+			}
+			c.Set(ConsumerKey, consumer.Name)
+		}
+		c.Set(JWTClaimsKey, claims)
+		c.Next()
+	}
+}
+
+// verify校验Authorization中的Bearer令牌，返回令牌中的声明
+func (jm *JWTAuthMiddleware) verify(c *gin.Context, issuer compiledJWTIssuer) (map[string]any, error) {
+	token, ok := jwt.BearerToken(c.GetHeader("Authorization"))
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+	return issuer.verifier.Verify(token, time.Now())
+}
+
+// issuer获取编译后的JWT签发者
+func (jm *JWTAuthMiddleware) issuer(name string) (compiledJWTIssuer, error) {
+	data, err := jm.JWTIssuerService.GetByName(context.Background(), name)
+	if err != nil {
+		return compiledJWTIssuer{}, err
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+	if cached, ok := jm.compiled[name]; ok && cached.updatedAt.Equal(data.UpdatedAt) {
+		return cached, nil
+	}
+	verifier, err := jwt.Compile(data.JWTOptions())
+	if err != nil {
+		return compiledJWTIssuer{}, err
+	}
+	compiled := compiledJWTIssuer{updatedAt: data.UpdatedAt, verifier: verifier, consumerClaim: data.ConsumerClaim}
+	jm.compiled[name] = compiled
+	return compiled, nil
+}
+
+// GetJWTClaims返回校验通过的JWT声明，路由未使用JWT认证时返回nil
+func GetJWTClaims(c *gin.Context) map[string]any {
+	if v, ok := c.Get(JWTClaimsKey); ok {
+		if claims, ok := v.(map[string]any); ok {
+			return claims
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/services"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type QuotaMiddleware struct {
+	QuotaService services.QuotaServiceImpl
+}
+
+func NewQuotaMiddleware(service services.QuotaServiceImpl) *QuotaMiddleware {
+	return &QuotaMiddleware{QuotaService: service}
+}
+
+// Quota根据认证消费者的使用计划限制每个周期的请求数和流量，配额用完时返回429
+// 需要放在流量统计中间件之前，请求完成后使用流量统计的结果累加流量
+func (qm *QuotaMiddleware) Quota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		consumer := GetConsumer(c)
+		if consumer == "" || IsBatchRequest(c) {
+			c.Next()
+			return
+		}
+
+		status, err := qm.QuotaService.Status(context.Background(), consumer)
+		if err != nil {
+			global.Logger.Error("加载配额失败", zap.String("consumer", consumer), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load quota"})
+			return
+		}
+		if status == nil {
+			c.Next()
+			return
+		}
+
+		// 先原子地占用一次请求的配额，避免并发请求同时通过检查后超出上限
+		reserved, err := qm.QuotaService.Reserve(context.Background(), status)
+		if err != nil {
+			global.Logger.Error("占用配额失败", zap.String("consumer", consumer), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load quota"})
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("X-Quota-Reset", strconv.FormatInt(int64(time.Until(status.ResetAt).Seconds()), 10))
+		if status.RequestLimit > 0 {
+			h.Set("X-Quota-Limit", strconv.FormatInt(status.RequestLimit, 10))
+			h.Set("X-Quota-Remaining", strconv.FormatInt(status.RemainingRequests, 10))
+		}
+		if status.ByteLimit > 0 {
+			h.Set("X-Quota-Bytes-Limit", strconv.FormatInt(status.ByteLimit, 10))
+			h.Set("X-Quota-Bytes-Remaining", strconv.FormatInt(status.RemainingBytes, 10))
+		}
+		if !reserved {
+			h.Set("Retry-After", h.Get("X-Quota-Reset"))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Quota exceeded"})
+			return
+		}
+
+		c.Next()
+
+		// 请求数已在占用时累加，这里只累加流量
+		bytes := c.GetInt64(TrafficInKey) + c.GetInt64(TrafficOutKey)
+		if err := qm.QuotaService.Record(context.Background(), status, 0, bytes); err != nil {
+			global.Logger.Error("记录配额使用量失败", zap.String("consumer", consumer), zap.Error(err))
+		}
+	}
+}
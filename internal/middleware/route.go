@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RouteKey 上下文中保存匹配到的API路由
+	RouteKey = "gateway_route"
+	// ConsumerKey 上下文中保存认证通过的消费者名称
+	ConsumerKey = "gateway_consumer"
+	// PathParamsKey 上下文中保存路由路径参数，*为路径模式之后的剩余路径
+	PathParamsKey = "gateway_path_params"
+)
+
+type RouteMiddleware struct {
+	APIService services.APIServiceImpl
+}
+
+func NewRouteMiddleware(apiService services.APIServiceImpl) *RouteMiddleware {
+	return &RouteMiddleware{APIService: apiService}
+}
+
+// ResolveRoute根据请求路径匹配API路由并保存到上下文中，未匹配时不做处理
+func (rm *RouteMiddleware) ResolveRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, params, err := rm.APIService.MatchPath(context.Background(), c.Request.URL.Path)
+		if err == nil {
+			c.Set(RouteKey, route)
+			c.Set(PathParamsKey, params)
+		}
+		c.Next()
+	}
+}
+
+// GetRoute获取上下文中匹配到的API路由
+func GetRoute(c *gin.Context) *model.APIInfo {
+	v, ok := c.Get(RouteKey)
+	if !ok {
+		return nil
+	}
+	route, _ := v.(*model.APIInfo)
+	return route
+}
+
+// GetPathParams获取上下文中的路由路径参数
+func GetPathParams(c *gin.Context) map[string]string {
+	params, _ := c.Value(PathParamsKey).(map[string]string)
+	return params
+}
+
+// IsBatchRequest判断请求是否为批量路由的外层请求，外层请求的流量和配额由各子请求分别统计
+func IsBatchRequest(c *gin.Context) bool {
+	route := GetRoute(c)
+	return route != nil && route.BatchLimit > 0
+}
+
+// GetConsumer获取上下文中认证通过的消费者名称
+func GetConsumer(c *gin.Context) string {
+	return c.GetString(ConsumerKey)
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/threat"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 策略未限制请求体大小时，解码压缩请求体进行结构检查的最大大小
+const maxDecodedThreatBody = 32 << 20
+
+type ThreatProtectionMiddleware struct {
+	ThreatPolicyService services.ThreatPolicyServiceImpl
+}
+
+func NewThreatProtectionMiddleware(service services.ThreatPolicyServiceImpl) *ThreatProtectionMiddleware {
+	return &ThreatProtectionMiddleware{ThreatPolicyService: service}
+}
+
+// ThreatProtection根据路由引用的威胁防护策略检查请求，违反限制时在转发前直接返回413或400
+func (tm *ThreatProtectionMiddleware) ThreatProtection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		if route == nil || route.ThreatPolicy == "" {
+			c.Next()
+			return
+		}
+
+		policy, err := tm.ThreatPolicyService.GetByName(context.Background(), route.ThreatPolicy)
+		if err != nil {
+			global.Logger.Error("加载威胁防护策略失败", zap.String("policy", route.ThreatPolicy), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load threat policy"})
+			return
+		}
+
+		status, err := checkRequest(c.Request, policy)
+		if err != nil {
+			global.Logger.Warn("请求违反威胁防护策略",
+				zap.String("api", route.Name),
+				zap.String("client_ip", c.ClientIP()),
+				zap.Error(err))
+			c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkRequest检查请求是否违反策略，返回违反时的状态码
+func checkRequest(req *http.Request, policy *model.ThreatPolicy) (int, error) {
+	if policy.MaxURLLength > 0 && len(req.RequestURI) > policy.MaxURLLength {
+		return http.StatusBadRequest, fmt.Errorf("url length exceeds %d", policy.MaxURLLength)
+	}
+	if policy.MaxQueryParams > 0 {
+		count := 0
+		for _, vv := range req.URL.Query() {
+			count += len(vv)
+		}
+		if count > policy.MaxQueryParams {
+			return http.StatusBadRequest, fmt.Errorf("query parameter count exceeds %d", policy.MaxQueryParams)
+		}
+	}
+
+	if policy.MaxHeaderCount > 0 || policy.MaxHeaderSize > 0 {
+		count, size := 0, 0
+		for name, vv := range req.Header {
+			for _, v := range vv {
+				count++
+				size += len(name) + len(v)
+			}
+		}
+		if policy.MaxHeaderCount > 0 && count > policy.MaxHeaderCount {
+			return http.StatusBadRequest, fmt.Errorf("header count exceeds %d", policy.MaxHeaderCount)
+		}
+		if policy.MaxHeaderSize > 0 && size > policy.MaxHeaderSize {
+			return http.StatusBadRequest, fmt.Errorf("header size exceeds %d", policy.MaxHeaderSize)
+		}
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return 0, nil
+	}
+	if policy.MaxBodySize > 0 && req.ContentLength > policy.MaxBodySize {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("body size exceeds %d", policy.MaxBodySize)
+	}
+
+	contentType := strings.ToLower(req.Header.Get("Content-Type"))
+	checkJSON := strings.Contains(contentType, "json")
+	checkXML := strings.Contains(contentType, "xml")
+	if policy.MaxBodySize <= 0 && !checkJSON && !checkXML {
+		return 0, nil
+	}
+
+	// 最多读取限制加1个字节，用于判断请求体是否超出限制
+	reader := io.Reader(req.Body)
+	if policy.MaxBodySize > 0 {
+		reader = io.LimitReader(req.Body, policy.MaxBodySize+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if policy.MaxBodySize > 0 && int64(len(body)) > policy.MaxBodySize {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("body size exceeds %d", policy.MaxBodySize)
+	}
+	req.Body = io.NopCloser(bytes.NewBuffer(body)) // 重置请求体，以便后续转发
+
+	// 结构检查针对解码后的内容，大小限制针对传输的字节数
+	if encoding := req.Header.Get("Content-Encoding"); encoding != "" && (checkJSON || checkXML) {
+		limit := policy.MaxBodySize
+		if limit <= 0 {
+			limit = maxDecodedThreatBody
+		}
+		body, err = compress.Decode(encoding, body, limit)
+		if errors.Is(err, compress.ErrTooLarge) {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("decoded body size exceeds %d", limit)
+		}
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("malformed %s body: %v", encoding, err)
+		}
+	}
+
+	switch {
+	case checkJSON:
+		err = threat.CheckJSON(body, threat.JSONLimits{
+			MaxDepth:        policy.JSONMaxDepth,
+			MaxArrayLength:  policy.JSONMaxArrayLength,
+			MaxStringLength: policy.JSONMaxStringLength,
+			MaxObjectKeys:   policy.JSONMaxObjectKeys,
+		})
+	case checkXML:
+		err = threat.CheckXML(body, threat.XMLLimits{
+			MaxDepth: policy.XMLMaxDepth,
+			AllowDTD: policy.XMLAllowDTD,
+		})
+	}
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	return 0, nil
+}
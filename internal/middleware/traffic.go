@@ -3,44 +3,58 @@ package middleware
 import (
 	"api-gateway/internal/model"
 	"api-gateway/internal/services"
-	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// TrafficInKey 上下文中保存入站流量字节数的键
+	TrafficInKey = "gateway_traffic_in"
+	// TrafficOutKey 上下文中保存出站流量字节数的键
+	TrafficOutKey = "gateway_traffic_out"
+)
+
 type TrafficMiddleware struct {
 	TrafficService services.TrafficService
 }
 
+func NewTrafficMiddleware(service services.TrafficService) *TrafficMiddleware {
+	return &TrafficMiddleware{TrafficService: service}
+}
+
 func (tm *TrafficMiddleware) TrafficStatsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 记录入站流量
-		var inTraffic bytes.Buffer
-		teeReader := io.TeeReader(c.Request.Body, &inTraffic)
-		c.Request.Body = io.NopCloser(teeReader)
+		// 批量请求的流量由各子请求分别记录，不重复统计外层请求
+		if IsBatchRequest(c) {
+			c.Next()
+			return
+		}
 
-		// 代理请求（这里假设你有代理请求的逻辑）
-		//...
+		// 记录入站流量
+		in := &countingReader{ReadCloser: c.Request.Body}
+		if c.Request.Body != nil {
+			c.Request.Body = in
+		}
 
 		// 记录出站流量
-		var outTraffic bytes.Buffer
-		c.Writer = &responseWriter{
-			ResponseWriter: c.Writer,
-			buffer:         &outTraffic,
-		}
+		writer := &responseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
 
 		c.Next()
 
 		// 计算流量大小并记录流量统计信息
 		apiName := c.Request.URL.Path
-		inSize := inTraffic.Len()
-		outSize := outTraffic.Len()
+		if route := GetRoute(c); route != nil {
+			apiName = route.Name
+		}
+		c.Set(TrafficInKey, in.n)
+		c.Set(TrafficOutKey, writer.n)
 		stats := model.TrafficStats{
 			API:        apiName,
-			InTraffic:  int64(inSize),
-			OutTraffic: int64(outSize),
+			InTraffic:  in.n,
+			OutTraffic: writer.n,
 		}
 		ctx := c.Request.Context()
 		err := tm.TrafficService.RecordTrafficStats(ctx, &stats)
@@ -50,13 +64,32 @@ func (tm *TrafficMiddleware) TrafficStatsMiddleware() gin.HandlerFunc {
 	}
 }
 
-// 自定义的ResponseWriter用于捕获出站流量
+// countingReader 统计读取的字节数
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// 自定义的ResponseWriter用于统计出站流量
 type responseWriter struct {
 	gin.ResponseWriter
-	buffer *bytes.Buffer
+	n int64
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	rw.buffer.Write(b)
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.n += int64(n)
+	return n, err
+}
+
+func (rw *responseWriter) WriteString(s string) (int, error) {
+	n, err := rw.ResponseWriter.WriteString(s)
+	rw.n += int64(n)
+	return n, err
 }
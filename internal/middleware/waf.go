@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+	"api-gateway/internal/services"
+	"api-gateway/pkg/compress"
+	"api-gateway/pkg/waf"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 默认检查的请求体大小
+const defaultWAFBodyLimit = 64 * 1024
+
+type WAFMiddleware struct {
+	WAFPolicyService services.WAFPolicyServiceImpl
+	WAFRuleService   services.WAFRuleServiceImpl
+
+	mu       sync.Mutex
+	compiled map[string]compiledWAFRule
+}
+
+// compiledWAFRule 编译后的自定义规则及其更新时间，规则更新后重新编译
+type compiledWAFRule struct {
+	updatedAt time.Time
+	rule      *waf.Rule
+}
+
+func NewWAFMiddleware(policyService services.WAFPolicyServiceImpl, ruleService services.WAFRuleServiceImpl) *WAFMiddleware {
+	return &WAFMiddleware{
+		WAFPolicyService: policyService,
+		WAFRuleService:   ruleService,
+		compiled:         make(map[string]compiledWAFRule),
+	}
+}
+
+// WAF根据路由引用的WAF策略检查请求，匹配的规则都会记录日志，拦截模式下返回403
+func (wm *WAFMiddleware) WAF() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := GetRoute(c)
+		if route == nil || route.WAFPolicy == "" {
+			c.Next()
+			return
+		}
+
+		policy, err := wm.WAFPolicyService.GetByName(context.Background(), route.WAFPolicy)
+		if err != nil {
+			global.Logger.Error("加载WAF策略失败", zap.String("policy", route.WAFPolicy), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load waf policy"})
+			return
+		}
+		rules, err := wm.rules(policy)
+		if err != nil {
+			global.Logger.Error("加载WAF规则失败", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load waf rules"})
+			return
+		}
+
+		input, err := wafInput(c.Request, policy.BodyLimit)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		matches := waf.Inspect(rules, input)
+		for _, m := range matches {
+			global.Logger.Warn("请求匹配WAF规则",
+				zap.String("rule_id", m.RuleID),
+				zap.String("category", m.Category),
+				zap.String("target", m.Target),
+				zap.String("name", m.Name),
+				zap.String("value", m.Value),
+				zap.String("mode", policy.Mode),
+				zap.String("api", route.Name),
+				zap.String("client_ip", c.ClientIP()))
+		}
+		if len(matches) > 0 && policy.Mode != waf.ModeDetect {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Request blocked by WAF"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// rules返回策略启用的内置规则和自定义规则
+func (wm *WAFMiddleware) rules(policy *model.WAFPolicy) ([]*waf.Rule, error) {
+	custom, err := wm.WAFRuleService.GetEnabled(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	all := append([]*waf.Rule{}, waf.Builtin()...)
+	wm.mu.Lock()
+	for _, data := range custom {
+		cached, ok := wm.compiled[data.RuleID]
+		if !ok || !cached.updatedAt.Equal(data.UpdatedAt) {
+			rule, err := waf.NewRule(data.RuleID, data.Category, data.Targets, data.Pattern, data.Description)
+			if err != nil {
+				global.Logger.Error("编译WAF规则失败", zap.String("rule_id", data.RuleID), zap.Error(err))
+				continue
+			}
+			cached = compiledWAFRule{updatedAt: data.UpdatedAt, rule: rule}
+			wm.compiled[data.RuleID] = cached
+		}
+		all = append(all, cached.rule)
+	}
+	wm.mu.Unlock()
+
+	disabled := make(map[string]bool, len(policy.DisabledRules))
+	for _, id := range policy.DisabledRules {
+		disabled[id] = true
+	}
+	categories := make(map[string]bool, len(policy.Categories))
+	for _, category := range policy.Categories {
+		categories[category] = true
+	}
+	rules := all[:0]
+	for _, rule := range all {
+		if disabled[rule.ID] || (len(categories) > 0 && !categories[rule.Category]) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// wafInput提取待检查的请求内容，只读取请求体的前limit个字节，读取的部分会放回请求体
+func wafInput(req *http.Request, limit int64) (*waf.Input, error) {
+	input := &waf.Input{
+		Path:    req.URL.EscapedPath(),
+		Query:   req.URL.RawQuery,
+		Headers: req.Header,
+	}
+	if limit == 0 {
+		limit = defaultWAFBodyLimit
+	}
+	if limit < 0 || req.Body == nil || req.Body == http.NoBody {
+		return input, nil
+	}
+
+	// 压缩的请求体解码后再检查，读取的原始字节会放回请求体
+	var raw bytes.Buffer
+	reader := io.TeeReader(req.Body, &raw)
+	encoding := req.Header.Get("Content-Encoding")
+	decoded := false
+	if encoding != "" && compress.Supported(encoding) {
+		if dec, err := compress.NewReader(encoding, reader); err == nil {
+			prefix, err := io.ReadAll(io.LimitReader(dec, limit))
+			// 读取的前缀可能截断压缩流，截断产生的错误不影响已解码的部分
+			if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+				input.Body = string(prefix)
+				decoded = true
+			}
+		}
+	}
+	if !decoded {
+		// 无法解码时检查原始内容，避免通过伪造的Content-Encoding绕过检查
+		if remaining := limit - int64(raw.Len()); remaining > 0 {
+			if _, err := io.Copy(io.Discard, io.LimitReader(reader, remaining)); err != nil {
+				return nil, err
+			}
+		}
+		input.Body = string(raw.Bytes()[:min(int64(raw.Len()), limit)])
+	}
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(raw.Bytes()), req.Body), req.Body}
+
+	input.Form = strings.HasPrefix(strings.ToLower(req.Header.Get("Content-Type")), "application/x-www-form-urlencoded")
+	return input, nil
+}
@@ -1,15 +1,60 @@
 package model
 
 import (
+	"api-gateway/pkg/aggregate"
+	"api-gateway/pkg/transform"
+
 	"gorm.io/gorm"
 )
 
 type APIInfo struct {
 	gorm.Model
-	Name        string
-	Path        string
-	Downstream  string
-	Description string
+	Name            string
+	Path            string // 路径模式，普通路径按前缀匹配，{name}或{name:正则}为路径参数，*匹配任意字符，以~开头时为正则表达式
+	Downstream      string
+	Description     string
+	AuthMode        string // 认证方式，为空不认证，hmac为HMAC请求签名认证，mtls为客户端证书认证，jwt为JWT Bearer令牌认证
+	ClockSkew       int    // 签名时间戳允许的偏差（秒），为0时使用默认值
+	ClientCA        string // 客户端证书认证使用的CA证书名称，为空时使用监听器校验的结果
+	JWTIssuer       string // JWT认证使用的签发者名称
+	CORSPolicy      string // 使用的跨域策略名称，为空不处理跨域
+	ThreatPolicy    string // 使用的威胁防护策略名称，为空不限制
+	WAFPolicy       string // 使用的WAF策略名称，为空不进行WAF检查
+	RedactionPolicy string // 流量记录使用的脱敏策略名称，为空时只使用全局规则
+	RateLimitPolicy string // 使用的限流策略名称，为空不限流
+	CachePolicy     string // 使用的响应缓存策略名称，为空不缓存
+	SOAPBridge      string // 使用的SOAP桥接名称，为空时按原协议转发
+	IdempotencyTTL  int    // 幂等键有效期（秒），大于0时POST和PATCH请求支持Idempotency-Key
+	// 合并相同并发GET请求时等待第一个请求结果的最长时间（毫秒），大于0时开启，超时后自行请求下游
+	CoalesceWait      int
+	CoalesceHeaders   []string `gorm:"serializer:json"` // 合并键中除方法、路径和查询参数外需要比较的请求头
+	DecompressRequest bool     // 下游无法处理压缩的请求体时开启，网关解压后再转发
+	// 转发前按顺序执行的请求头转换规则，在下游的规则之后执行
+	RequestHeaders []transform.HeaderRule `gorm:"serializer:json"`
+	// 返回客户端前按顺序执行的响应头转换规则，在下游的规则之后执行
+	ResponseHeaders []transform.HeaderRule `gorm:"serializer:json"`
+	// 转发路径模板，如/v2/customers/{id}/purchases，为空时使用请求路径
+	RewritePath  string
+	StripPrefix  string                // 转发前去掉的路径前缀，设置了RewritePath时不生效
+	AddPrefix    string                // 转发前添加的路径前缀
+	QueryRules   []transform.QueryRule `gorm:"serializer:json"` // 转发前按顺序执行的查询参数转换规则
+	RequestBody  []transform.BodyRule  `gorm:"serializer:json"` // 转发前对JSON请求体按顺序执行的转换规则
+	ResponseBody []transform.BodyRule  `gorm:"serializer:json"` // 对下游2xx的JSON响应体按顺序执行的转换规则
+	// 聚合路由的子请求，不为空时该路由不转发到Downstream，而是请求各子请求的下游并合并结果
+	Aggregation      []aggregate.Call `gorm:"serializer:json"`
+	BatchLimit       int              // 大于0时该路由为批量请求端点，值为一次最多的子请求数
+	BatchConcurrency int              // 批量请求同时执行的子请求数，为0时使用默认值
 }
 
 func (md *APIInfo) GetID() uint { return md.ID }
+
+// RewriteOptions返回转发前的路径和查询参数改写配置，params为路径模式中的参数名称
+func (md *APIInfo) RewriteOptions(params []string) transform.RewriteOptions {
+	return transform.RewriteOptions{
+		Path:        md.RewritePath,
+		Params:      params,
+		StripPrefix: md.StripPrefix,
+		AddPrefix:   md.AddPrefix,
+		Query:       md.QueryRules,
+	}
+}
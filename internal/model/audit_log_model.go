@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"api-gateway/pkg/jsondiff"
+)
+
+// AuditLog 管理操作审计记录，只追加不修改
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+	Actor     string    `gorm:"index"` // 操作人
+	SourceIP  string
+	Method    string
+	Path      string
+	Resource  string `gorm:"index"` // 资源类型，如apis、downstream
+	Name      string `gorm:"index"` // 资源名称
+	Action    string // create、update、delete，其他操作为对应的路径名称
+	Status    int
+	Before    string            // 变更前的JSON
+	After     string            // 变更后的JSON
+	Diff      []jsondiff.Change `gorm:"serializer:json"`
+}
+
+func (md *AuditLog) GetID() uint { return md.ID }
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// CachePolicy 响应缓存策略，只缓存GET请求，HEAD请求可以使用GET的缓存
+type CachePolicy struct {
+	gorm.Model
+	Name string `gorm:"unique"`
+	// 强制缓存时间（秒），大于0时忽略上游的max-age和Expires，但仍遵守no-store和private
+	TTL int
+	// 参与缓存键的请求头，上游响应的Vary会额外生效
+	KeyHeaders []string `gorm:"serializer:json"`
+	// 参与缓存键的查询参数，为空时使用完整的查询字符串
+	KeyQuery []string `gorm:"serializer:json"`
+	// 缓存键是否包含认证的消费者，不同消费者的响应不同时需要开启
+	KeyConsumer bool
+	// 过期后在后台重新验证期间仍可使用的时间（秒），大于0时覆盖上游的stale-while-revalidate
+	StaleWhileRevalidate int
+	// 上游出错时仍可使用过期条目的时间（秒），大于0时覆盖上游的stale-if-error
+	StaleIfError int
+	MaxEntrySize int // 允许缓存的最大响应体（字节），为0时使用默认值
+	Description  string
+}
+
+func (md *CachePolicy) GetID() uint { return md.ID }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Certificate 证书信息，证书和私钥文件保存在运行目录中，数据库只保存元数据
+type Certificate struct {
+	gorm.Model
+	Name       string   `gorm:"unique"`
+	Type       string   // 证书类型，ca为CA证书包，cert为带私钥的证书
+	Subject    string   // 证书主题
+	Issuer     string   // 颁发者
+	DNSNames   []string `gorm:"serializer:json"`
+	NotBefore  time.Time
+	NotAfter   time.Time
+	PEM        string `gorm:"-" json:",omitempty"` // 上传的证书内容（PEM），不保存到数据库
+	PrivateKey string `gorm:"-" json:",omitempty"` // 上传的私钥内容（PEM），不保存到数据库
+}
+
+func (md *Certificate) GetID() uint { return md.ID }
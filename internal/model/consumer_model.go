@@ -0,0 +1,28 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// Consumer 调用网关的客户端（消费者）
+type Consumer struct {
+	gorm.Model
+	Name        string `gorm:"unique"`
+	Secret      string `gorm:"serializer:secret"` // HMAC签名使用的共享密钥，加密存储，只在创建时返回
+	Description string
+	CertSubject string   // 映射到该消费者的客户端证书主题，如CN=client,O=Acme
+	CertSANs    []string `gorm:"serializer:json"` // 映射到该消费者的客户端证书SAN（域名、邮箱或URI）
+	UsagePlan   string   // 使用计划名称，为空不限制配额
+}
+
+func (md *Consumer) GetID() uint { return md.ID }
+
+// MaskSecrets将共享密钥替换为占位符，用于管理接口的返回结果
+func (md *Consumer) MaskSecrets() {
+	md.Secret = maskSecret(md.Secret)
+}
+
+// UnmaskSecrets清除更新数据中的占位符，未修改的共享密钥保留原值
+func (md *Consumer) UnmaskSecrets() {
+	md.Secret = unmaskSecret(md.Secret)
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"api-gateway/pkg/cors"
+
+	"gorm.io/gorm"
+)
+
+// CORSPolicy 跨域策略，API通过名称引用，多个API可共用同一策略
+type CORSPolicy struct {
+	gorm.Model
+	Name             string   `gorm:"unique"`
+	AllowOrigins     []string `gorm:"serializer:json"` // 允许的来源，支持精确匹配、*、https://*.example.com通配子域名和~开头的正则表达式
+	AllowMethods     []string `gorm:"serializer:json"` // 允许的请求方法，为空时为GET、HEAD、POST
+	AllowHeaders     []string `gorm:"serializer:json"` // 允许的请求头，为空或包含*时允许所有
+	ExposeHeaders    []string `gorm:"serializer:json"` // 允许浏览器读取的响应头
+	AllowCredentials bool
+	MaxAge           int // 预检结果缓存时间（秒）
+	Description      string
+}
+
+func (md *CORSPolicy) GetID() uint { return md.ID }
+
+// CORSOptions转换为CORS策略配置
+func (md *CORSPolicy) CORSOptions() cors.Options {
+	return cors.Options{
+		AllowOrigins:     md.AllowOrigins,
+		AllowMethods:     md.AllowMethods,
+		AllowHeaders:     md.AllowHeaders,
+		ExposeHeaders:    md.ExposeHeaders,
+		AllowCredentials: md.AllowCredentials,
+		MaxAge:           md.MaxAge,
+	}
+}
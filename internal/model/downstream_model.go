@@ -1,13 +1,52 @@
 package model
 
 import (
+	"api-gateway/pkg/transform"
+
 	"gorm.io/gorm"
 )
 
 type Downstream struct {
 	gorm.Model
-	Name string `gorm:"unique"`
-	URL  string
+	Name    string `gorm:"unique"`
+	URL     string
+	Signing SigningProfile `gorm:"embedded;embeddedPrefix:sign_"`
+	TLS     TLSSettings    `gorm:"embedded;embeddedPrefix:tls_"`
+	// 转发到该下游的所有路由共用的请求头和响应头转换规则
+	RequestHeaders  []transform.HeaderRule `gorm:"serializer:json"`
+	ResponseHeaders []transform.HeaderRule `gorm:"serializer:json"`
 }
 
 func (md *Downstream) GetID() uint { return md.ID }
+
+// MaskSecrets将签名密钥替换为占位符，用于管理接口的返回结果
+func (md *Downstream) MaskSecrets() {
+	md.Signing.SecretKey = maskSecret(md.Signing.SecretKey)
+	md.Signing.SessionToken = maskSecret(md.Signing.SessionToken)
+}
+
+// UnmaskSecrets清除更新数据中的占位符，未修改的签名密钥保留原值
+func (md *Downstream) UnmaskSecrets() {
+	md.Signing.SecretKey = unmaskSecret(md.Signing.SecretKey)
+	md.Signing.SessionToken = unmaskSecret(md.Signing.SessionToken)
+}
+
+// SigningProfile 转发到下游时的请求签名配置
+type SigningProfile struct {
+	Type          string // 签名方式，为空不签名，aws-sigv4或hmac
+	Region        string // AWS区域
+	Service       string // AWS服务名，如s3
+	AccessKey     string // AWS AccessKey或HMAC签名的KeyID
+	SecretKey     string `gorm:"serializer:secret"` // AWS SecretKey或HMAC共享密钥，加密存储，只写
+	SessionToken  string `gorm:"serializer:secret"` // AWS临时凭证的SessionToken，加密存储，只写
+	SignedHeaders string // HMAC签名包含的请求头，以分号分隔
+}
+
+// TLSSettings 连接下游时的TLS配置，证书通过名称引用证书管理中的证书
+type TLSSettings struct {
+	CA                 string // 校验下游证书的CA证书名称，为空使用系统CA
+	ClientCert         string // 双向TLS使用的客户端证书名称
+	ServerName         string // 覆盖SNI及证书校验使用的主机名
+	MinVersion         string // 最低TLS版本，如1.2、1.3
+	InsecureSkipVerify bool   // 跳过证书校验，仅用于开发环境，release模式下拒绝使用
+}
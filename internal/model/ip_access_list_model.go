@@ -0,0 +1,18 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// IPAccessList IP访问控制列表
+type IPAccessList struct {
+	gorm.Model
+	Name        string   `gorm:"unique"`
+	API         string   // 作用的API名称，为空表示全局
+	Allow       []string `gorm:"serializer:json"` // 允许的IP或CIDR，不为空时只允许列表中的地址
+	Deny        []string `gorm:"serializer:json"` // 拒绝的IP或CIDR，优先于允许列表
+	Description string
+	DeniedCount int64 // 被该列表拒绝的请求数
+}
+
+func (md *IPAccessList) GetID() uint { return md.ID }
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"api-gateway/pkg/jwt"
+
+	"gorm.io/gorm"
+)
+
+// JWTIssuer JWT签发者配置，使用jwt认证方式的API通过名称引用，网关校验令牌后才能使用令牌中的声明
+type JWTIssuer struct {
+	gorm.Model
+	Name          string   `gorm:"unique"`
+	Issuer        string   // 要求的iss声明，为空不校验
+	Audience      string   // 要求的aud声明，为空不校验
+	Algorithms    []string `gorm:"serializer:json"`   // 允许的签名算法，如HS256、RS256、ES256，为空时根据密钥类型选择
+	Secret        string   `gorm:"serializer:secret"` // HS*算法的共享密钥，加密存储，只写
+	PublicKey     string   // RS*和ES*算法的PEM公钥或证书
+	ClockSkew     int      // exp和nbf允许的时钟偏差（秒）
+	ConsumerClaim string   // 映射到消费者名称的声明，如sub，为空时不关联消费者
+	Description   string
+}
+
+func (md *JWTIssuer) GetID() uint { return md.ID }
+
+// JWTOptions转换为JWT校验配置
+func (md *JWTIssuer) JWTOptions() jwt.Options {
+	return jwt.Options{
+		Issuer:     md.Issuer,
+		Audience:   md.Audience,
+		Algorithms: md.Algorithms,
+		Secret:     md.Secret,
+		PublicKey:  md.PublicKey,
+		ClockSkew:  time.Duration(md.ClockSkew) * time.Second,
+	}
+}
+
+// MaskSecrets将共享密钥替换为占位符，用于管理接口的返回结果
+func (md *JWTIssuer) MaskSecrets() {
+	md.Secret = maskSecret(md.Secret)
+}
+
+// UnmaskSecrets清除更新数据中的占位符，未修改的共享密钥保留原值
+func (md *JWTIssuer) UnmaskSecrets() {
+	md.Secret = unmaskSecret(md.Secret)
+}
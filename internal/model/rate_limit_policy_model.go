@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"api-gateway/pkg/ratelimit"
+
+	"gorm.io/gorm"
+)
+
+// RateLimitPolicy 限流策略，API通过名称引用，每个API单独计数
+type RateLimitPolicy struct {
+	gorm.Model
+	Name      string `gorm:"unique"`
+	Algorithm string // token-bucket为令牌桶，sliding-window为滑动窗口
+	Rate      int    // 每个周期允许的请求数
+	Period    int    // 周期（秒）
+	Burst     int    // 令牌桶容量，为0时等于Rate
+	// 限流维度，可组合使用：ip为客户端IP，consumer为认证的消费者，header:<名称>为请求头的值
+	// 为空时所有请求共用一个计数
+	KeyBy       []string `gorm:"serializer:json"`
+	Description string
+}
+
+func (md *RateLimitPolicy) GetID() uint { return md.ID }
+
+// Limit转换为限流规则
+func (md *RateLimitPolicy) Limit() ratelimit.Limit {
+	return ratelimit.Limit{
+		Algorithm: md.Algorithm,
+		Rate:      md.Rate,
+		Period:    time.Duration(md.Period) * time.Second,
+		Burst:     md.Burst,
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"api-gateway/pkg/redact"
+
+	"gorm.io/gorm"
+)
+
+// RedactionPolicy 流量记录脱敏策略，API通过名称引用，在全局规则的基础上追加规则
+type RedactionPolicy struct {
+	gorm.Model
+	Name        string   `gorm:"unique"`
+	Headers     []string `gorm:"serializer:json"` // 脱敏的请求头和响应头名称
+	JSONPaths   []string `gorm:"serializer:json"` // 脱敏的JSON字段路径，如user.password、items.*.token、**.secret
+	FormFields  []string `gorm:"serializer:json"` // 脱敏的查询参数和表单字段名称
+	Patterns    []string `gorm:"serializer:json"` // 脱敏的正则表达式，支持@card、@email、@idcard
+	Override    bool     // 是否忽略配置文件中的全局规则，默认的凭证脱敏规则始终生效
+	Description string
+}
+
+func (md *RedactionPolicy) GetID() uint { return md.ID }
+
+// Rules转换为脱敏规则
+func (md *RedactionPolicy) Rules() redact.Rules {
+	return redact.Rules{
+		Headers:    md.Headers,
+		JSONPaths:  md.JSONPaths,
+		FormFields: md.FormFields,
+		Patterns:   md.Patterns,
+	}
+}
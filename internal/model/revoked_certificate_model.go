@@ -0,0 +1,15 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// RevokedCertificate 本地维护的证书吊销列表
+type RevokedCertificate struct {
+	gorm.Model
+	SerialNumber string `gorm:"unique"` // 证书序列号（十六进制，小写）
+	Issuer       string // 颁发者，仅用于说明
+	Reason       string
+}
+
+func (md *RevokedCertificate) GetID() uint { return md.ID }
@@ -0,0 +1,20 @@
+package model
+
+// SecretMask 管理接口返回的密钥占位符，密钥只写不读，更新时传入空值或占位符表示保留原密钥
+const SecretMask = "******"
+
+// maskSecret将已设置的密钥替换为占位符
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return SecretMask
+}
+
+// unmaskSecret将占位符还原为空值，更新时不会覆盖已保存的密钥
+func unmaskSecret(secret string) string {
+	if secret == SecretMask {
+		return ""
+	}
+	return secret
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"api-gateway/pkg/soap"
+
+	"gorm.io/gorm"
+)
+
+// SOAPBridge SOAP桥接，引用该桥接的API接受JSON请求，转换为SOAP请求转发，并把SOAP响应转换为JSON
+type SOAPBridge struct {
+	gorm.Model
+	Name    string `gorm:"unique"`
+	Version string // SOAP版本，1.1或1.2，为空时为1.1
+	Action  string // SOAPAction
+	// 生成soap:Body内容的Go模板，数据为.Body（JSON请求体）、.Query、.Path和.Header，字符串已转义为XML文本
+	Template    string
+	Description string
+}
+
+func (md *SOAPBridge) GetID() uint { return md.ID }
+
+// SOAPOptions转换为SOAP桥接配置
+func (md *SOAPBridge) SOAPOptions() soap.Options {
+	return soap.Options{
+		Version:  md.Version,
+		Action:   md.Action,
+		Template: md.Template,
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// ThreatPolicy 请求威胁防护策略，API通过名称引用，限制值为0表示不限制
+type ThreatPolicy struct {
+	gorm.Model
+	Name                string `gorm:"unique"`
+	MaxBodySize         int64  // 请求体最大字节数，超出返回413
+	MaxHeaderCount      int    // 请求头最大数量
+	MaxHeaderSize       int    // 请求头总大小（字节）
+	MaxURLLength        int    // URL最大长度
+	MaxQueryParams      int    // 查询参数最大数量
+	JSONMaxDepth        int    // JSON最大嵌套深度
+	JSONMaxArrayLength  int    // JSON数组最大元素数
+	JSONMaxStringLength int    // JSON字符串最大长度
+	JSONMaxObjectKeys   int    // JSON对象最大键数
+	XMLMaxDepth         int    // XML最大嵌套深度
+	XMLAllowDTD         bool   // 是否允许XML的DOCTYPE声明，默认禁止以防止实体扩展
+	Description         string
+}
+
+func (md *ThreatPolicy) GetID() uint { return md.ID }
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// UsagePlan 使用计划，消费者通过名称引用，在自然日或自然月内限制请求数和流量
+type UsagePlan struct {
+	gorm.Model
+	Name         string `gorm:"unique"`
+	Period       string // day为自然日，month为自然月
+	RequestLimit int64  // 每个周期的请求数上限，0为不限制
+	ByteLimit    int64  // 每个周期的流量上限（入站和出站字节数之和），0为不限制
+	Timezone     string // 周期重置使用的时区，如Asia/Shanghai，为空时使用配置文件中的时区
+	Description  string
+}
+
+func (md *UsagePlan) GetID() uint { return md.ID }
+
+// QuotaUsage 消费者在一个配额周期内的使用量
+type QuotaUsage struct {
+	gorm.Model
+	Consumer  string `gorm:"uniqueIndex:idx_quota_usage"`
+	Plan      string `gorm:"uniqueIndex:idx_quota_usage"`
+	PeriodKey string `gorm:"uniqueIndex:idx_quota_usage"` // 周期标识，如2024-05-01或2024-05
+	Requests  int64
+	Bytes     int64
+}
+
+func (md *QuotaUsage) GetID() uint { return md.ID }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// WAFRule 自定义WAF规则，与内置规则一起生效
+type WAFRule struct {
+	gorm.Model
+	RuleID      string   `gorm:"unique"` // 规则ID，不能与内置规则重复
+	Category    string   // 规则分类，如sqli、xss、path-traversal、command-injection
+	Targets     []string `gorm:"serializer:json"` // 检查的请求部位：path、query、headers、body，为空时检查所有部位
+	Pattern     string   // 正则表达式
+	Description string
+	Disabled    bool // 是否停用
+}
+
+func (md *WAFRule) GetID() uint { return md.ID }
+
+// WAFPolicy WAF策略，API通过名称引用，未引用策略的API不进行WAF检查
+type WAFPolicy struct {
+	gorm.Model
+	Name          string   `gorm:"unique"`
+	Mode          string   // block为拦截，detect为仅记录日志
+	Categories    []string `gorm:"serializer:json"` // 启用的规则分类，为空时启用所有分类
+	DisabledRules []string `gorm:"serializer:json"` // 在该策略中停用的规则ID
+	BodyLimit     int64    // 检查的请求体最大字节数，0为默认值，负数表示不检查请求体
+	Description   string
+}
+
+func (md *WAFPolicy) GetID() uint { return md.ID }
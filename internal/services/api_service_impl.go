@@ -2,7 +2,9 @@ package services
 
 import (
 	"api-gateway/pkg/service"
+	"api-gateway/pkg/transform"
 	"context"
+	"sync"
 
 	"api-gateway/internal/global"
 	"api-gateway/internal/model"
@@ -22,6 +24,7 @@ func NewAPIService() APIServiceImpl {
 }
 
 func (as *APIServiceImpl) Add(ctx context.Context, apiInfo *model.APIInfo) error {
+	defer routes.invalidate()
 	return as.baseService.Create(ctx, apiInfo)
 }
 
@@ -47,16 +50,19 @@ func (as *APIServiceImpl) GetAll(ctx context.Context) ([]*model.APIInfo, error)
 }
 
 func (as *APIServiceImpl) Update(ctx context.Context, apiInfo model.APIInfo) error {
+	defer routes.invalidate()
 	return as.baseService.UpdateById(ctx, &apiInfo)
 }
 
 func (as *APIServiceImpl) UpdateByName(ctx context.Context, apiInfo model.APIInfo, name string) error {
+	defer routes.invalidate()
 	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
 		return tx.Where("name = ?", name)
 	})
 }
 
 func (as *APIServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	defer routes.invalidate()
 	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
 		return tx.Where("name = ?", name)
 	})
@@ -69,17 +75,118 @@ func (as *APIServiceImpl) GetById(ctx context.Context, id uint) (*model.APIInfo,
 }
 
 func (as *APIServiceImpl) Adds(ctx context.Context, apiInfos []*model.APIInfo) error {
+	defer routes.invalidate()
 	return as.baseService.CreateBatch(ctx, apiInfos)
 }
 
 func (as *APIServiceImpl) UpdateById(ctx context.Context, apiInfo model.APIInfo, id uint) error {
+	defer routes.invalidate()
 	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
 		return tx.Where("id = ?", id)
 	})
 }
 
 func (as *APIServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	defer routes.invalidate()
 	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
 		return tx.Where("id = ?", id)
 	})
 }
+
+// MatchPath根据请求路径查找匹配的API及路径参数，多个API匹配时取字面部分最长的一个
+// 使用内存中的路由表匹配，返回的API由所有请求共享，调用方不能修改
+func (as *APIServiceImpl) MatchPath(ctx context.Context, path string) (*model.APIInfo, map[string]string, error) {
+	table, err := routes.get(ctx, as)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		matched     *model.APIInfo
+		params      map[string]string
+		specificity int
+	)
+	for _, r := range table {
+		p, ok := r.pattern.Match(path)
+		if !ok {
+			continue
+		}
+		if matched == nil || r.pattern.Specificity() > specificity {
+			matched, params, specificity = r.info, p, r.pattern.Specificity()
+		}
+	}
+	if matched == nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	return matched, params, nil
+}
+
+// route 路由表中的API及编译后的路径模式
+type route struct {
+	info    *model.APIInfo
+	pattern *transform.PathPattern
+}
+
+// routeTable 内存中的路由表，API新增、修改或删除后失效，下一次匹配时重新加载
+type routeTable struct {
+	mu      sync.RWMutex
+	version uint64 // 每次失效时递增，加载期间发生的修改不会被旧数据覆盖
+	loaded  uint64 // 当前路由表对应的版本，为0表示未加载
+	routes  []route
+}
+
+// 管理接口和网关在同一进程中，共享同一个路由表
+var routes = &routeTable{version: 1}
+
+// invalidate使路由表失效
+func (rt *routeTable) invalidate() {
+	rt.mu.Lock()
+	rt.version++
+	rt.mu.Unlock()
+}
+
+// get返回路由表，失效时从数据库重新加载
+func (rt *routeTable) get(ctx context.Context, as *APIServiceImpl) ([]route, error) {
+	rt.mu.RLock()
+	version, loaded, table := rt.version, rt.loaded, rt.routes
+	rt.mu.RUnlock()
+	if loaded == version {
+		return table, nil
+	}
+
+	list, err := as.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table = make([]route, 0, len(list))
+	for _, info := range list {
+		pattern, err := CompilePathPattern(info.Path)
+		if err != nil {
+			continue
+		}
+		table = append(table, route{info: info, pattern: pattern})
+	}
+
+	rt.mu.Lock()
+	if rt.version == version {
+		rt.loaded, rt.routes = version, table
+	}
+	rt.mu.Unlock()
+	return table, nil
+}
+
+// 编译后的路径模式，按模式字符串缓存
+var pathPatterns sync.Map
+
+// CompilePathPattern编译API的路径模式，编译结果会被缓存
+func CompilePathPattern(path string) (*transform.PathPattern, error) {
+	if cached, ok := pathPatterns.Load(path); ok {
+		return cached.(*transform.PathPattern), nil
+	}
+	pattern, err := transform.CompilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	pathPatterns.Store(path, pattern)
+	return pattern, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+	"time"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditQuery 审计记录查询条件，空值表示不过滤
+type AuditQuery struct {
+	Actor    string
+	Resource string
+	Name     string
+	Action   string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+	Offset   int
+}
+
+type AuditLogServiceImpl struct {
+	baseService service.BaseService[*model.AuditLog]
+}
+
+func NewAuditLogService() AuditLogServiceImpl {
+	bs := service.NewBaseService(&model.AuditLog{}, global.DB)
+	return AuditLogServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *AuditLogServiceImpl) Add(ctx context.Context, data *model.AuditLog) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *AuditLogServiceImpl) GetById(ctx context.Context, id uint) (*model.AuditLog, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+// Query按条件查询审计记录，按时间倒序返回
+func (as *AuditLogServiceImpl) Query(ctx context.Context, query AuditQuery) ([]*model.AuditLog, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		if query.Actor != "" {
+			tx = tx.Where("actor = ?", query.Actor)
+		}
+		if query.Resource != "" {
+			tx = tx.Where("resource = ?", query.Resource)
+		}
+		if query.Name != "" {
+			tx = tx.Where("name = ?", query.Name)
+		}
+		if query.Action != "" {
+			tx = tx.Where("action = ?", query.Action)
+		}
+		if !query.Since.IsZero() {
+			tx = tx.Where("created_at >= ?", query.Since)
+		}
+		if !query.Until.IsZero() {
+			tx = tx.Where("created_at < ?", query.Until)
+		}
+		return tx.Order("id DESC").Limit(query.Limit).Offset(query.Offset)
+	})
+}
+
+// Purge删除指定时间之前的审计记录，返回删除的记录数
+func (as *AuditLogServiceImpl) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := as.baseService.GetDB().WithContext(ctx).Where("created_at < ?", before).Delete(&model.AuditLog{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CachePolicyServiceImpl struct {
+	baseService service.BaseService[*model.CachePolicy]
+}
+
+func NewCachePolicyService() CachePolicyServiceImpl {
+	bs := service.NewBaseService(&model.CachePolicy{}, global.DB)
+	return CachePolicyServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *CachePolicyServiceImpl) Add(ctx context.Context, apiInfo *model.CachePolicy) error {
+	return as.baseService.Create(ctx, apiInfo)
+}
+
+func (as *CachePolicyServiceImpl) GetByName(ctx context.Context, name string) (*model.CachePolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CachePolicyServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.CachePolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *CachePolicyServiceImpl) GetAll(ctx context.Context) ([]*model.CachePolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *CachePolicyServiceImpl) Update(ctx context.Context, apiInfo model.CachePolicy) error {
+	return as.baseService.UpdateById(ctx, &apiInfo)
+}
+
+func (as *CachePolicyServiceImpl) UpdateByName(ctx context.Context, apiInfo model.CachePolicy, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CachePolicyServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CachePolicyServiceImpl) GetById(ctx context.Context, id uint) (*model.CachePolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *CachePolicyServiceImpl) Adds(ctx context.Context, apiInfos []*model.CachePolicy) error {
+	return as.baseService.CreateBatch(ctx, apiInfos)
+}
+
+func (as *CachePolicyServiceImpl) UpdateById(ctx context.Context, apiInfo model.CachePolicy, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *CachePolicyServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CertificateServiceImpl struct {
+	baseService service.BaseService[*model.Certificate]
+}
+
+func NewCertificateService() CertificateServiceImpl {
+	bs := service.NewBaseService(&model.Certificate{}, global.DB)
+	return CertificateServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *CertificateServiceImpl) Add(ctx context.Context, data *model.Certificate) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *CertificateServiceImpl) GetByName(ctx context.Context, name string) (*model.Certificate, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CertificateServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.Certificate, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *CertificateServiceImpl) GetAll(ctx context.Context) ([]*model.Certificate, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *CertificateServiceImpl) Update(ctx context.Context, data model.Certificate) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *CertificateServiceImpl) UpdateByName(ctx context.Context, data model.Certificate, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CertificateServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CertificateServiceImpl) GetById(ctx context.Context, id uint) (*model.Certificate, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *CertificateServiceImpl) Adds(ctx context.Context, datas []*model.Certificate) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *CertificateServiceImpl) UpdateById(ctx context.Context, data model.Certificate, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *CertificateServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
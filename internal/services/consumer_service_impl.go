@@ -0,0 +1,114 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+	"crypto/x509"
+	"slices"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ConsumerServiceImpl struct {
+	baseService service.BaseService[*model.Consumer]
+}
+
+func NewConsumerService() ConsumerServiceImpl {
+	bs := service.NewBaseService(&model.Consumer{}, global.DB)
+	return ConsumerServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *ConsumerServiceImpl) Add(ctx context.Context, data *model.Consumer) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *ConsumerServiceImpl) GetByName(ctx context.Context, name string) (*model.Consumer, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *ConsumerServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.Consumer, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *ConsumerServiceImpl) GetAll(ctx context.Context) ([]*model.Consumer, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *ConsumerServiceImpl) Update(ctx context.Context, data model.Consumer) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *ConsumerServiceImpl) UpdateByName(ctx context.Context, data model.Consumer, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *ConsumerServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *ConsumerServiceImpl) GetById(ctx context.Context, id uint) (*model.Consumer, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *ConsumerServiceImpl) Adds(ctx context.Context, datas []*model.Consumer) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *ConsumerServiceImpl) UpdateById(ctx context.Context, data model.Consumer, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *ConsumerServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+// MatchCertificate根据客户端证书的主题或SAN查找映射的消费者
+func (as *ConsumerServiceImpl) MatchCertificate(ctx context.Context, cert *x509.Certificate) (*model.Consumer, error) {
+	list, err := as.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := cert.Subject.String()
+	sans := append([]string{}, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+
+	for _, consumer := range list {
+		if consumer.CertSubject != "" && consumer.CertSubject == subject {
+			return consumer, nil
+		}
+		for _, san := range consumer.CertSANs {
+			if slices.Contains(sans, san) {
+				return consumer, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
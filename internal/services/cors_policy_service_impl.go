@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CORSPolicyServiceImpl struct {
+	baseService service.BaseService[*model.CORSPolicy]
+}
+
+func NewCORSPolicyService() CORSPolicyServiceImpl {
+	bs := service.NewBaseService(&model.CORSPolicy{}, global.DB)
+	return CORSPolicyServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *CORSPolicyServiceImpl) Add(ctx context.Context, data *model.CORSPolicy) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *CORSPolicyServiceImpl) GetByName(ctx context.Context, name string) (*model.CORSPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CORSPolicyServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.CORSPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *CORSPolicyServiceImpl) GetAll(ctx context.Context) ([]*model.CORSPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *CORSPolicyServiceImpl) Update(ctx context.Context, data model.CORSPolicy) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *CORSPolicyServiceImpl) UpdateByName(ctx context.Context, data model.CORSPolicy, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CORSPolicyServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *CORSPolicyServiceImpl) GetById(ctx context.Context, id uint) (*model.CORSPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *CORSPolicyServiceImpl) Adds(ctx context.Context, datas []*model.CORSPolicy) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *CORSPolicyServiceImpl) UpdateById(ctx context.Context, data model.CORSPolicy, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *CORSPolicyServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
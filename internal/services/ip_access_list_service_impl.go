@@ -0,0 +1,98 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type IPAccessListServiceImpl struct {
+	baseService service.BaseService[*model.IPAccessList]
+}
+
+func NewIPAccessListService() IPAccessListServiceImpl {
+	bs := service.NewBaseService(&model.IPAccessList{}, global.DB)
+	return IPAccessListServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *IPAccessListServiceImpl) Add(ctx context.Context, data *model.IPAccessList) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *IPAccessListServiceImpl) GetByName(ctx context.Context, name string) (*model.IPAccessList, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *IPAccessListServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.IPAccessList, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *IPAccessListServiceImpl) GetAll(ctx context.Context) ([]*model.IPAccessList, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *IPAccessListServiceImpl) Update(ctx context.Context, data model.IPAccessList) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *IPAccessListServiceImpl) UpdateByName(ctx context.Context, data model.IPAccessList, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *IPAccessListServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *IPAccessListServiceImpl) GetById(ctx context.Context, id uint) (*model.IPAccessList, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *IPAccessListServiceImpl) Adds(ctx context.Context, datas []*model.IPAccessList) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *IPAccessListServiceImpl) UpdateById(ctx context.Context, data model.IPAccessList, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *IPAccessListServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+// GetForAPI获取全局及指定API的访问控制列表
+func (as *IPAccessListServiceImpl) GetForAPI(ctx context.Context, api string) ([]*model.IPAccessList, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("api = ? OR api = ?", "", api)
+	})
+}
+
+// IncrDenied增加访问控制列表的拒绝计数
+func (as *IPAccessListServiceImpl) IncrDenied(ctx context.Context, id uint) error {
+	return as.baseService.GetDB().WithContext(ctx).Model(&model.IPAccessList{}).
+		Where("id = ?", id).UpdateColumn("denied_count", gorm.Expr("denied_count + ?", 1)).Error
+}
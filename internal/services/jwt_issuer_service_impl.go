@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type JWTIssuerServiceImpl struct {
+	baseService service.BaseService[*model.JWTIssuer]
+}
+
+func NewJWTIssuerService() JWTIssuerServiceImpl {
+	bs := service.NewBaseService(&model.JWTIssuer{}, global.DB)
+	return JWTIssuerServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *JWTIssuerServiceImpl) Add(ctx context.Context, data *model.JWTIssuer) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *JWTIssuerServiceImpl) GetByName(ctx context.Context, name string) (*model.JWTIssuer, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *JWTIssuerServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.JWTIssuer, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *JWTIssuerServiceImpl) GetAll(ctx context.Context) ([]*model.JWTIssuer, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *JWTIssuerServiceImpl) Update(ctx context.Context, data model.JWTIssuer) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *JWTIssuerServiceImpl) UpdateByName(ctx context.Context, data model.JWTIssuer, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *JWTIssuerServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *JWTIssuerServiceImpl) GetById(ctx context.Context, id uint) (*model.JWTIssuer, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *JWTIssuerServiceImpl) Adds(ctx context.Context, datas []*model.JWTIssuer) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *JWTIssuerServiceImpl) UpdateById(ctx context.Context, data model.JWTIssuer, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *JWTIssuerServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
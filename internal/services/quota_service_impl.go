@@ -0,0 +1,178 @@
+package services
+
+import (
+	"api-gateway/pkg/quota"
+	"api-gateway/pkg/service"
+	"context"
+	"time"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuotaStatus 消费者当前周期的配额使用情况，上限为0表示不限制
+type QuotaStatus struct {
+	Consumer          string
+	Plan              string
+	Period            string
+	Window            string
+	ResetAt           time.Time
+	Requests          int64
+	RequestLimit      int64
+	RemainingRequests int64
+	Bytes             int64
+	ByteLimit         int64
+	RemainingBytes    int64
+}
+
+// Exhausted判断配额是否已用完
+func (qs *QuotaStatus) Exhausted() bool {
+	return (qs.RequestLimit > 0 && qs.Requests >= qs.RequestLimit) ||
+		(qs.ByteLimit > 0 && qs.Bytes >= qs.ByteLimit)
+}
+
+type QuotaServiceImpl struct {
+	baseService     service.BaseService[*model.QuotaUsage]
+	consumerService ConsumerServiceImpl
+	planService     UsagePlanServiceImpl
+	location        *time.Location // 默认时区
+}
+
+func NewQuotaService(location *time.Location) QuotaServiceImpl {
+	bs := service.NewBaseService(&model.QuotaUsage{}, global.DB)
+	return QuotaServiceImpl{
+		baseService:     bs,
+		consumerService: NewConsumerService(),
+		planService:     NewUsagePlanService(),
+		location:        location,
+	}
+}
+
+// Status获取消费者当前周期的配额使用情况，消费者未分配使用计划时返回nil
+func (as *QuotaServiceImpl) Status(ctx context.Context, consumerName string) (*QuotaStatus, error) {
+	consumer, err := as.consumerService.GetByName(ctx, consumerName)
+	if err != nil {
+		return nil, err
+	}
+	if consumer.UsagePlan == "" {
+		return nil, nil
+	}
+	plan, err := as.planService.GetByName(ctx, consumer.UsagePlan)
+	if err != nil {
+		return nil, err
+	}
+	window, err := as.window(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &QuotaStatus{
+		Consumer:     consumerName,
+		Plan:         plan.Name,
+		Period:       plan.Period,
+		Window:       window.Key,
+		ResetAt:      window.End,
+		RequestLimit: plan.RequestLimit,
+		ByteLimit:    plan.ByteLimit,
+	}
+	var usage model.QuotaUsage
+	err = as.baseService.GetDB().WithContext(ctx).
+		Where("consumer = ? AND plan = ? AND period_key = ?", consumerName, plan.Name, window.Key).
+		Limit(1).Find(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	status.Requests, status.Bytes = usage.Requests, usage.Bytes
+	status.RemainingRequests = remaining(plan.RequestLimit, usage.Requests)
+	status.RemainingBytes = remaining(plan.ByteLimit, usage.Bytes)
+	return status, nil
+}
+
+// Reserve在配额未用完时原子地为一次请求占用配额，并用占用后的使用量更新status，
+// 并发请求不会在检查和累加之间超出上限，配额已用完时返回false
+func (as *QuotaServiceImpl) Reserve(ctx context.Context, status *QuotaStatus) (bool, error) {
+	db := as.baseService.GetDB().WithContext(ctx)
+	usage := &model.QuotaUsage{Consumer: status.Consumer, Plan: status.Plan, PeriodKey: status.Window}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "consumer"}, {Name: "plan"}, {Name: "period_key"}},
+		DoNothing: true,
+	}).Create(usage).Error
+	if err != nil {
+		return false, err
+	}
+
+	tx := db.Model(&model.QuotaUsage{}).
+		Where("consumer = ? AND plan = ? AND period_key = ?", status.Consumer, status.Plan, status.Window)
+	if status.RequestLimit > 0 {
+		tx = tx.Where("requests < ?", status.RequestLimit)
+	}
+	if status.ByteLimit > 0 {
+		tx = tx.Where("bytes < ?", status.ByteLimit)
+	}
+	result := tx.Updates(map[string]any{
+		"requests":   gorm.Expr("requests + 1"),
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	var current model.QuotaUsage
+	err = db.Where("consumer = ? AND plan = ? AND period_key = ?", status.Consumer, status.Plan, status.Window).
+		Limit(1).Find(&current).Error
+	if err != nil {
+		return false, err
+	}
+	status.Requests, status.Bytes = current.Requests, current.Bytes
+	status.RemainingRequests = remaining(status.RequestLimit, current.Requests)
+	status.RemainingBytes = remaining(status.ByteLimit, current.Bytes)
+	return result.RowsAffected > 0, nil
+}
+
+// Record累加消费者在指定周期内的使用量
+func (as *QuotaServiceImpl) Record(ctx context.Context, status *QuotaStatus, requests, bytes int64) error {
+	usage := &model.QuotaUsage{
+		Consumer:  status.Consumer,
+		Plan:      status.Plan,
+		PeriodKey: status.Window,
+		Requests:  requests,
+		Bytes:     bytes,
+	}
+	return as.baseService.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "consumer"}, {Name: "plan"}, {Name: "period_key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests":   gorm.Expr("requests + ?", requests),
+			"bytes":      gorm.Expr("bytes + ?", bytes),
+			"updated_at": time.Now(),
+		}),
+	}).Create(usage).Error
+}
+
+// Reset清空消费者当前周期的使用量
+func (as *QuotaServiceImpl) Reset(ctx context.Context, status *QuotaStatus) error {
+	return as.baseService.GetDB().WithContext(ctx).Model(&model.QuotaUsage{}).
+		Where("consumer = ? AND plan = ? AND period_key = ?", status.Consumer, status.Plan, status.Window).
+		Updates(map[string]any{"requests": 0, "bytes": 0}).Error
+}
+
+// window返回使用计划当前的配额周期
+func (as *QuotaServiceImpl) window(plan *model.UsagePlan) (quota.Window, error) {
+	loc := as.location
+	if plan.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(plan.Timezone); err != nil {
+			return quota.Window{}, err
+		}
+	}
+	return quota.Current(plan.Period, loc, time.Now())
+}
+
+func remaining(limit, used int64) int64 {
+	if limit <= 0 || used >= limit {
+		return 0
+	}
+	return limit - used
+}
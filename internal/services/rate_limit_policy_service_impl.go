@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RateLimitPolicyServiceImpl struct {
+	baseService service.BaseService[*model.RateLimitPolicy]
+}
+
+func NewRateLimitPolicyService() RateLimitPolicyServiceImpl {
+	bs := service.NewBaseService(&model.RateLimitPolicy{}, global.DB)
+	return RateLimitPolicyServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *RateLimitPolicyServiceImpl) Add(ctx context.Context, apiInfo *model.RateLimitPolicy) error {
+	return as.baseService.Create(ctx, apiInfo)
+}
+
+func (as *RateLimitPolicyServiceImpl) GetByName(ctx context.Context, name string) (*model.RateLimitPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.RateLimitPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) GetAll(ctx context.Context) ([]*model.RateLimitPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) Update(ctx context.Context, apiInfo model.RateLimitPolicy) error {
+	return as.baseService.UpdateById(ctx, &apiInfo)
+}
+
+func (as *RateLimitPolicyServiceImpl) UpdateByName(ctx context.Context, apiInfo model.RateLimitPolicy, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) GetById(ctx context.Context, id uint) (*model.RateLimitPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) Adds(ctx context.Context, apiInfos []*model.RateLimitPolicy) error {
+	return as.baseService.CreateBatch(ctx, apiInfos)
+}
+
+func (as *RateLimitPolicyServiceImpl) UpdateById(ctx context.Context, apiInfo model.RateLimitPolicy, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *RateLimitPolicyServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
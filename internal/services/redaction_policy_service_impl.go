@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RedactionPolicyServiceImpl struct {
+	baseService service.BaseService[*model.RedactionPolicy]
+}
+
+func NewRedactionPolicyService() RedactionPolicyServiceImpl {
+	bs := service.NewBaseService(&model.RedactionPolicy{}, global.DB)
+	return RedactionPolicyServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *RedactionPolicyServiceImpl) Add(ctx context.Context, apiInfo *model.RedactionPolicy) error {
+	return as.baseService.Create(ctx, apiInfo)
+}
+
+func (as *RedactionPolicyServiceImpl) GetByName(ctx context.Context, name string) (*model.RedactionPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.RedactionPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) GetAll(ctx context.Context) ([]*model.RedactionPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) Update(ctx context.Context, apiInfo model.RedactionPolicy) error {
+	return as.baseService.UpdateById(ctx, &apiInfo)
+}
+
+func (as *RedactionPolicyServiceImpl) UpdateByName(ctx context.Context, apiInfo model.RedactionPolicy, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) GetById(ctx context.Context, id uint) (*model.RedactionPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) Adds(ctx context.Context, apiInfos []*model.RedactionPolicy) error {
+	return as.baseService.CreateBatch(ctx, apiInfos)
+}
+
+func (as *RedactionPolicyServiceImpl) UpdateById(ctx context.Context, apiInfo model.RedactionPolicy, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *RedactionPolicyServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RevokedCertificateServiceImpl struct {
+	baseService service.BaseService[*model.RevokedCertificate]
+}
+
+func NewRevokedCertificateService() RevokedCertificateServiceImpl {
+	bs := service.NewBaseService(&model.RevokedCertificate{}, global.DB)
+	return RevokedCertificateServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *RevokedCertificateServiceImpl) Add(ctx context.Context, data *model.RevokedCertificate) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *RevokedCertificateServiceImpl) GetBySerialNumber(ctx context.Context, serialNumber string) (*model.RevokedCertificate, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("serial_number = ?", serialNumber)
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.RevokedCertificate, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) GetAll(ctx context.Context) ([]*model.RevokedCertificate, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) Update(ctx context.Context, data model.RevokedCertificate) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *RevokedCertificateServiceImpl) UpdateBySerialNumber(ctx context.Context, data model.RevokedCertificate, serialNumber string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("serial_number = ?", serialNumber)
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) DeleteBySerialNumber(ctx context.Context, serialNumber string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("serial_number = ?", serialNumber)
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) GetById(ctx context.Context, id uint) (*model.RevokedCertificate, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) Adds(ctx context.Context, datas []*model.RevokedCertificate) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *RevokedCertificateServiceImpl) UpdateById(ctx context.Context, data model.RevokedCertificate, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *RevokedCertificateServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+// IsRevoked判断证书序列号是否已被吊销
+func (as *RevokedCertificateServiceImpl) IsRevoked(ctx context.Context, serialNumber string) (bool, error) {
+	var count int64
+	err := as.baseService.GetDB().WithContext(ctx).Model(&model.RevokedCertificate{}).
+		Where("serial_number = ?", serialNumber).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type SOAPBridgeServiceImpl struct {
+	baseService service.BaseService[*model.SOAPBridge]
+}
+
+func NewSOAPBridgeService() SOAPBridgeServiceImpl {
+	bs := service.NewBaseService(&model.SOAPBridge{}, global.DB)
+	return SOAPBridgeServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *SOAPBridgeServiceImpl) Add(ctx context.Context, apiInfo *model.SOAPBridge) error {
+	return as.baseService.Create(ctx, apiInfo)
+}
+
+func (as *SOAPBridgeServiceImpl) GetByName(ctx context.Context, name string) (*model.SOAPBridge, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.SOAPBridge, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) GetAll(ctx context.Context) ([]*model.SOAPBridge, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) Update(ctx context.Context, apiInfo model.SOAPBridge) error {
+	return as.baseService.UpdateById(ctx, &apiInfo)
+}
+
+func (as *SOAPBridgeServiceImpl) UpdateByName(ctx context.Context, apiInfo model.SOAPBridge, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) GetById(ctx context.Context, id uint) (*model.SOAPBridge, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) Adds(ctx context.Context, apiInfos []*model.SOAPBridge) error {
+	return as.baseService.CreateBatch(ctx, apiInfos)
+}
+
+func (as *SOAPBridgeServiceImpl) UpdateById(ctx context.Context, apiInfo model.SOAPBridge, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *SOAPBridgeServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
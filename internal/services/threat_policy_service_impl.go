@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ThreatPolicyServiceImpl struct {
+	baseService service.BaseService[*model.ThreatPolicy]
+}
+
+func NewThreatPolicyService() ThreatPolicyServiceImpl {
+	bs := service.NewBaseService(&model.ThreatPolicy{}, global.DB)
+	return ThreatPolicyServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *ThreatPolicyServiceImpl) Add(ctx context.Context, data *model.ThreatPolicy) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *ThreatPolicyServiceImpl) GetByName(ctx context.Context, name string) (*model.ThreatPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.ThreatPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) GetAll(ctx context.Context) ([]*model.ThreatPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) Update(ctx context.Context, data model.ThreatPolicy) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *ThreatPolicyServiceImpl) UpdateByName(ctx context.Context, data model.ThreatPolicy, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) GetById(ctx context.Context, id uint) (*model.ThreatPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) Adds(ctx context.Context, datas []*model.ThreatPolicy) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *ThreatPolicyServiceImpl) UpdateById(ctx context.Context, data model.ThreatPolicy, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *ThreatPolicyServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
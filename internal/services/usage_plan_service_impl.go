@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type UsagePlanServiceImpl struct {
+	baseService service.BaseService[*model.UsagePlan]
+}
+
+func NewUsagePlanService() UsagePlanServiceImpl {
+	bs := service.NewBaseService(&model.UsagePlan{}, global.DB)
+	return UsagePlanServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *UsagePlanServiceImpl) Add(ctx context.Context, apiInfo *model.UsagePlan) error {
+	return as.baseService.Create(ctx, apiInfo)
+}
+
+func (as *UsagePlanServiceImpl) GetByName(ctx context.Context, name string) (*model.UsagePlan, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *UsagePlanServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.UsagePlan, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *UsagePlanServiceImpl) GetAll(ctx context.Context) ([]*model.UsagePlan, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *UsagePlanServiceImpl) Update(ctx context.Context, apiInfo model.UsagePlan) error {
+	return as.baseService.UpdateById(ctx, &apiInfo)
+}
+
+func (as *UsagePlanServiceImpl) UpdateByName(ctx context.Context, apiInfo model.UsagePlan, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *UsagePlanServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *UsagePlanServiceImpl) GetById(ctx context.Context, id uint) (*model.UsagePlan, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *UsagePlanServiceImpl) Adds(ctx context.Context, apiInfos []*model.UsagePlan) error {
+	return as.baseService.CreateBatch(ctx, apiInfos)
+}
+
+func (as *UsagePlanServiceImpl) UpdateById(ctx context.Context, apiInfo model.UsagePlan, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *UsagePlanServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type WAFPolicyServiceImpl struct {
+	baseService service.BaseService[*model.WAFPolicy]
+}
+
+func NewWAFPolicyService() WAFPolicyServiceImpl {
+	bs := service.NewBaseService(&model.WAFPolicy{}, global.DB)
+	return WAFPolicyServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *WAFPolicyServiceImpl) Add(ctx context.Context, apiInfo *model.WAFPolicy) error {
+	return as.baseService.Create(ctx, apiInfo)
+}
+
+func (as *WAFPolicyServiceImpl) GetByName(ctx context.Context, name string) (*model.WAFPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *WAFPolicyServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.WAFPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *WAFPolicyServiceImpl) GetAll(ctx context.Context) ([]*model.WAFPolicy, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *WAFPolicyServiceImpl) Update(ctx context.Context, apiInfo model.WAFPolicy) error {
+	return as.baseService.UpdateById(ctx, &apiInfo)
+}
+
+func (as *WAFPolicyServiceImpl) UpdateByName(ctx context.Context, apiInfo model.WAFPolicy, name string) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *WAFPolicyServiceImpl) DeleteByName(ctx context.Context, name string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	})
+}
+
+func (as *WAFPolicyServiceImpl) GetById(ctx context.Context, id uint) (*model.WAFPolicy, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *WAFPolicyServiceImpl) Adds(ctx context.Context, apiInfos []*model.WAFPolicy) error {
+	return as.baseService.CreateBatch(ctx, apiInfos)
+}
+
+func (as *WAFPolicyServiceImpl) UpdateById(ctx context.Context, apiInfo model.WAFPolicy, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &apiInfo, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *WAFPolicyServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"api-gateway/pkg/service"
+	"context"
+
+	"api-gateway/internal/global"
+	"api-gateway/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type WAFRuleServiceImpl struct {
+	baseService service.BaseService[*model.WAFRule]
+}
+
+func NewWAFRuleService() WAFRuleServiceImpl {
+	bs := service.NewBaseService(&model.WAFRule{}, global.DB)
+	return WAFRuleServiceImpl{
+		baseService: bs,
+	}
+}
+
+func (as *WAFRuleServiceImpl) Add(ctx context.Context, data *model.WAFRule) error {
+	return as.baseService.Create(ctx, data)
+}
+
+func (as *WAFRuleServiceImpl) GetByRuleID(ctx context.Context, ruleID string) (*model.WAFRule, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("rule_id = ?", ruleID)
+	})
+}
+
+func (as *WAFRuleServiceImpl) GetByCondition(ctx context.Context, conditions map[string]any) ([]*model.WAFRule, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		for key, value := range conditions {
+			tx = tx.Where(key, value)
+		}
+		return tx
+	})
+}
+
+func (as *WAFRuleServiceImpl) GetAll(ctx context.Context) ([]*model.WAFRule, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx
+	})
+}
+
+func (as *WAFRuleServiceImpl) Update(ctx context.Context, data model.WAFRule) error {
+	return as.baseService.UpdateById(ctx, &data)
+}
+
+func (as *WAFRuleServiceImpl) UpdateByRuleID(ctx context.Context, data model.WAFRule, ruleID string) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("rule_id = ?", ruleID)
+	})
+}
+
+func (as *WAFRuleServiceImpl) DeleteByRuleID(ctx context.Context, ruleID string) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("rule_id = ?", ruleID)
+	})
+}
+
+func (as *WAFRuleServiceImpl) GetById(ctx context.Context, id uint) (*model.WAFRule, error) {
+	return as.baseService.GetByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *WAFRuleServiceImpl) Adds(ctx context.Context, datas []*model.WAFRule) error {
+	return as.baseService.CreateBatch(ctx, datas)
+}
+
+func (as *WAFRuleServiceImpl) UpdateById(ctx context.Context, data model.WAFRule, id uint) error {
+	return as.baseService.UpdateByCondition(ctx, &data, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+func (as *WAFRuleServiceImpl) DeleteById(ctx context.Context, id uint) error {
+	return as.baseService.DeleteByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+}
+
+// GetEnabled获取所有启用的自定义规则
+func (as *WAFRuleServiceImpl) GetEnabled(ctx context.Context) ([]*model.WAFRule, error) {
+	return as.baseService.GetAllByCondition(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("disabled = ?", false)
+	})
+}
+
+// SetDisabled设置规则是否停用
+func (as *WAFRuleServiceImpl) SetDisabled(ctx context.Context, ruleID string, disabled bool) error {
+	return as.baseService.GetDB().WithContext(ctx).Model(&model.WAFRule{}).
+		Where("rule_id = ?", ruleID).Update("disabled", disabled).Error
+}
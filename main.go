@@ -1,7 +1,19 @@
 package main
 
-import "api-gateway/bootstrap"
+import (
+	"fmt"
+	"os"
+
+	"api-gateway/bootstrap"
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := bootstrap.RunCommand(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	bootstrap.Run()
 }
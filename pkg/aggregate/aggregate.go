@@ -0,0 +1,352 @@
+package aggregate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-gateway/pkg/transform"
+)
+
+// ErrorsField 合并文档中记录可选子请求失败原因的字段，子请求不能使用该名称
+const ErrorsField = "errors"
+
+// DefaultTimeout 子请求未配置超时时间时使用的超时时间
+const DefaultTimeout = 10 * time.Second
+
+// Call 聚合路由的子请求
+//
+// Path和Headers中的值支持模板变量：请求变量（如{query.id}）、路由路径参数（如{id}），
+// 以及依赖的子请求结果，如{calls.user.address.city}，数组元素使用下标，如{calls.orders.0.id}
+type Call struct {
+	Name        string            // 结果在合并文档中的字段名
+	Downstream  string            // 下游服务名称
+	Method      string            // 请求方法，为空时为GET
+	Path        string            // 路径模板，可以包含查询参数，变量的值会进行路径转义
+	Headers     map[string]string // 请求头模板，客户端的请求头不会自动转发
+	ForwardBody bool              // 是否转发客户端的请求体
+	DependsOn   []string          // 依赖的子请求，依赖全部成功后才会发起
+	Timeout     int               // 超时时间（毫秒），为0时使用默认值
+	Required    bool              // 必需的子请求失败时整个请求失败，可选的子请求失败时返回部分结果
+}
+
+// Request 发往下游的子请求
+type Request struct {
+	Downstream string
+	Method     string
+	Path       string // 路径及查询参数
+	Header     http.Header
+	Body       []byte
+}
+
+// Response 子请求的响应
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// SendFunc 发送子请求
+type SendFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Plan 编译后的聚合计划
+type Plan struct {
+	calls []*compiledCall
+}
+
+type compiledCall struct {
+	Call
+	path    *transform.Template
+	query   *transform.Template // 路径模板中?之后的部分，变量的值进行查询参数转义
+	headers map[string]*transform.Template
+	timeout time.Duration
+}
+
+// Compile校验并编译子请求，params为路由路径模式中的参数名称
+func Compile(calls []Call, params []string) (*Plan, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("no calls")
+	}
+	names := make(map[string]*Call, len(calls))
+	for i := range calls {
+		call := &calls[i]
+		switch {
+		case call.Name == "" || strings.Contains(call.Name, "."):
+			return nil, fmt.Errorf("call %d: invalid name %q", i, call.Name)
+		case call.Name == ErrorsField:
+			return nil, fmt.Errorf("call %d: name %q is reserved", i, call.Name)
+		case names[call.Name] != nil:
+			return nil, fmt.Errorf("call %d: duplicate name %q", i, call.Name)
+		case call.Downstream == "":
+			return nil, fmt.Errorf("call %s: downstream is required", call.Name)
+		case call.Timeout < 0:
+			return nil, fmt.Errorf("call %s: timeout must not be negative", call.Name)
+		}
+		names[call.Name] = call
+	}
+
+	pathParams := make(map[string]bool, len(params)+1)
+	pathParams["*"] = true
+	for _, name := range params {
+		pathParams[name] = true
+	}
+	plan := &Plan{}
+	for i := range calls {
+		call := calls[i]
+		for _, dep := range call.DependsOn {
+			if names[dep] == nil {
+				return nil, fmt.Errorf("call %s: unknown dependency %q", call.Name, dep)
+			}
+		}
+		// 模板只能引用直接依赖的子请求的结果
+		known := func(name string) bool {
+			if pathParams[name] || transform.RequestVariable(name) {
+				return true
+			}
+			if rest, ok := strings.CutPrefix(name, "calls."); ok {
+				dep, _, _ := strings.Cut(rest, ".")
+				for _, d := range call.DependsOn {
+					if d == dep {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		c := &compiledCall{Call: call, headers: make(map[string]*transform.Template)}
+		c.Method = strings.ToUpper(call.Method)
+		if c.Method == "" {
+			c.Method = http.MethodGet
+		}
+		if !strings.HasPrefix(call.Path, "/") {
+			return nil, fmt.Errorf("call %s: path must start with /", call.Name)
+		}
+		var err error
+		path, query, _ := strings.Cut(call.Path, "?")
+		if c.path, err = transform.ParseTemplate(path, known); err != nil {
+			return nil, fmt.Errorf("call %s: %v", call.Name, err)
+		}
+		if c.query, err = transform.ParseTemplate(query, known); err != nil {
+			return nil, fmt.Errorf("call %s: %v", call.Name, err)
+		}
+		for name, value := range call.Headers {
+			if c.headers[http.CanonicalHeaderKey(name)], err = transform.ParseTemplate(value, known); err != nil {
+				return nil, fmt.Errorf("call %s: header %s: %v", call.Name, name, err)
+			}
+		}
+		c.timeout = time.Duration(call.Timeout) * time.Millisecond
+		if c.timeout == 0 {
+			c.timeout = DefaultTimeout
+		}
+		plan.calls = append(plan.calls, c)
+	}
+	if err := checkCycles(names); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// checkCycles检查子请求之间的循环依赖
+func checkCycles(calls map[string]*Call) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(calls))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("circular dependency at call %s", name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range calls[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for name := range calls {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Result 聚合的结果
+type Result struct {
+	Document map[string]any    // 合并的JSON文档，失败的子请求为null
+	Errors   map[string]string // 失败的子请求及原因
+	Failed   string            // 失败的必需子请求，为空表示成功
+}
+
+// Partial判断是否有可选的子请求失败
+func (r *Result) Partial() bool {
+	return r.Failed == "" && len(r.Errors) > 0
+}
+
+// outcome 子请求的执行结果
+type outcome struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// Run执行子请求，没有依赖关系的子请求并行执行，body为客户端的请求体
+func (p *Plan) Run(ctx context.Context, vars *transform.RequestVars, body []byte, send SendFunc) *Result {
+	outcomes := make(map[string]*outcome, len(p.calls))
+	for _, call := range p.calls {
+		outcomes[call.Name] = &outcome{done: make(chan struct{})}
+	}
+	for _, call := range p.calls {
+		go func(call *compiledCall) {
+			o := outcomes[call.Name]
+			defer close(o.done)
+			for _, dep := range call.DependsOn {
+				d := outcomes[dep]
+				<-d.done
+				if d.err != nil {
+					o.err = fmt.Errorf("dependency %s failed", dep)
+					return
+				}
+			}
+			o.value, o.err = call.run(ctx, vars, outcomes, body, send)
+		}(call)
+	}
+
+	result := &Result{Document: make(map[string]any, len(p.calls))}
+	for _, call := range p.calls {
+		o := outcomes[call.Name]
+		<-o.done
+		result.Document[call.Name] = o.value
+		if o.err == nil {
+			continue
+		}
+		if result.Errors == nil {
+			result.Errors = make(map[string]string)
+		}
+		result.Errors[call.Name] = o.err.Error()
+		if call.Required && result.Failed == "" {
+			result.Failed = call.Name
+		}
+	}
+	if len(result.Errors) > 0 {
+		result.Document[ErrorsField] = result.Errors
+	}
+	return result
+}
+
+// run发送子请求，依赖的子请求都已完成
+func (c *compiledCall) run(ctx context.Context, vars *transform.RequestVars, outcomes map[string]*outcome, body []byte, send SendFunc) (any, error) {
+	lookup := func(name string) (string, bool) {
+		if rest, ok := strings.CutPrefix(name, "calls."); ok {
+			dep, path, _ := strings.Cut(rest, ".")
+			return lookupValue(outcomes[dep].value, path)
+		}
+		if value, ok := vars.PathParams[name]; ok {
+			return value, true
+		}
+		return vars.Lookup(name)
+	}
+	req := &Request{
+		Downstream: c.Downstream,
+		Method:     c.Method,
+		Path: c.path.Execute(transform.VarsFunc(func(name string) (string, bool) {
+			value, ok := lookup(name)
+			return url.PathEscape(value), ok
+		})),
+		Header: make(http.Header),
+	}
+	// 路径参数或上游结果中的.和..不会被转义，转义的/在发送前解码后同样会形成路径段，
+	// 因此按解码后的路径检查，不能让子请求跳出配置的路径
+	if decoded, err := url.PathUnescape(req.Path); err != nil || transform.UnsafePath(decoded) {
+		return nil, transform.ErrUnsafePath
+	}
+	if query := c.query.Execute(transform.VarsFunc(func(name string) (string, bool) {
+		value, ok := lookup(name)
+		return url.QueryEscape(value), ok
+	})); query != "" {
+		req.Path += "?" + query
+	}
+	for name, tmpl := range c.headers {
+		req.Header.Set(name, strings.NewReplacer("\r", " ", "\n", " ").Replace(tmpl.Execute(transform.VarsFunc(lookup))))
+	}
+	if c.ForwardBody {
+		req.Body = body
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := send(ctx, req)
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.New("timeout")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status < http.StatusOK || resp.Status >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("status %d", resp.Status)
+	}
+	return decodeBody(resp), nil
+}
+
+// decodeBody解码JSON响应体，不是JSON时返回字符串
+func decodeBody(resp *Response) any {
+	if len(bytes.TrimSpace(resp.Body)) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(resp.Body))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return string(resp.Body)
+	}
+	return v
+}
+
+// lookupValue按.分隔的路径查找子请求结果中的值，数组使用下标
+func lookupValue(value any, path string) (string, bool) {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch v := value.(type) {
+			case map[string]any:
+				var ok bool
+				if value, ok = v[key]; !ok {
+					return "", false
+				}
+			case []any:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(v) {
+					return "", false
+				}
+				value = v[i]
+			default:
+				return "", false
+			}
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "", false
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
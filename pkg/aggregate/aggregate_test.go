@@ -0,0 +1,83 @@
+package aggregate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"api-gateway/pkg/transform"
+)
+
+// recorder 记录发出的子请求，返回固定的JSON响应
+type recorder struct {
+	mu    sync.Mutex
+	paths map[string]string
+	body  map[string]string
+}
+
+func (r *recorder) send(ctx context.Context, req *Request) (*Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths[req.Downstream] = req.Path
+	return &Response{Status: http.StatusOK, Body: []byte(r.body[req.Downstream])}, nil
+}
+
+func TestRunRejectsDotSegments(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		query  string
+		header string
+		first  string // 依赖的子请求返回的next值
+	}{
+		{name: "query", path: "/api/search/{query.x}", query: "../../admin"},
+		{name: "dot segment", path: "/api/search/{query.x}/items", query: ".."},
+		{name: "header", path: "/api/{header.X-Path}", header: "../admin"},
+		{name: "dependency", path: "/api/search/{calls.first.next}", first: "../../admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Compile([]Call{
+				{Name: "first", Downstream: "first", Path: "/first"},
+				{Name: "second", Downstream: "second", Path: tt.path, DependsOn: []string{"first"}, Required: true},
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/agg", nil)
+			q := req.URL.Query()
+			q.Set("x", tt.query)
+			req.URL.RawQuery = q.Encode()
+			req.Header.Set("X-Path", tt.header)
+			rec := &recorder{
+				paths: map[string]string{},
+				body:  map[string]string{"first": `{"next":"` + tt.first + `"}`},
+			}
+			result := plan.Run(context.Background(), &transform.RequestVars{Request: req}, nil, rec.send)
+			if result.Failed != "second" || result.Errors["second"] != transform.ErrUnsafePath.Error() {
+				t.Fatalf("result = %+v, want second to fail with unsafe path", result)
+			}
+			if path, ok := rec.paths["second"]; ok {
+				t.Fatalf("unsafe call was sent with path %q", path)
+			}
+		})
+	}
+}
+
+func TestRunEscapesPathValues(t *testing.T) {
+	plan, err := Compile([]Call{{Name: "search", Downstream: "s", Path: "/api/search/{query.x}?q={query.x}"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/agg?x=a/b%26c", nil)
+	rec := &recorder{paths: map[string]string{}, body: map[string]string{"s": `{}`}}
+	result := plan.Run(context.Background(), &transform.RequestVars{Request: req}, nil, rec.send)
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors %v", result.Errors)
+	}
+	if got, want := rec.paths["s"], "/api/search/a%2Fb&c?q=a%2Fb%26c"; got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+}
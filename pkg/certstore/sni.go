@@ -0,0 +1,162 @@
+package certstore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SNIResolver 根据TLS握手中的SNI从多个证书存储中选择证书，支持证书文件变化后热加载
+type SNIResolver struct {
+	stores  []*Store
+	allowed map[string]bool
+
+	mu          sync.RWMutex
+	exact       map[string]*tls.Certificate
+	wildcard    map[string]*tls.Certificate
+	defaultCert *tls.Certificate
+	fingerprint string
+
+	// OnReload在每次重新加载证书后调用，参数为加载的证书
+	OnReload func(certs map[string]*x509.Certificate)
+}
+
+// NewSNIResolver创建SNI证书选择器，names不为空时只使用指定名称的证书
+func NewSNIResolver(names []string, stores ...*Store) *SNIResolver {
+	r := &SNIResolver{stores: stores}
+	if len(names) > 0 {
+		r.allowed = make(map[string]bool, len(names))
+		for _, name := range names {
+			r.allowed[name] = true
+		}
+	}
+	return r
+}
+
+// GetCertificate用于tls.Config.GetCertificate，按精确域名、通配符域名、默认证书的顺序选择证书
+func (r *SNIResolver) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if cert, ok := r.exact[name]; ok {
+		return cert, nil
+	}
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := r.wildcard[name[i+1:]]; ok {
+			return cert, nil
+		}
+	}
+	if r.defaultCert != nil {
+		return r.defaultCert, nil
+	}
+	return nil, errors.New("no certificate available")
+}
+
+// Reload重新加载所有证书
+func (r *SNIResolver) Reload() error {
+	exact := make(map[string]*tls.Certificate)
+	wildcard := make(map[string]*tls.Certificate)
+	leafs := make(map[string]*x509.Certificate)
+	var defaultCert *tls.Certificate
+	var errs []error
+
+	for _, store := range r.stores {
+		names, err := store.List()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if (r.allowed != nil && !r.allowed[name]) || !store.HasKey(name) {
+				continue
+			}
+			cert, err := store.Certificate(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("load certificate %q: %v", name, err))
+				continue
+			}
+			leafs[name] = cert.Leaf
+			if defaultCert == nil {
+				defaultCert = cert
+			}
+			for _, host := range certificateHosts(cert.Leaf) {
+				if strings.HasPrefix(host, "*.") {
+					wildcard[host[2:]] = cert
+				} else {
+					exact[host] = cert
+				}
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.exact, r.wildcard, r.defaultCert = exact, wildcard, defaultCert
+	r.mu.Unlock()
+
+	if r.OnReload != nil {
+		r.OnReload(leafs)
+	}
+	return errors.Join(errs...)
+}
+
+// Watch定期检查证书文件，发生变化时重新加载，onError用于报告加载错误
+func (r *SNIResolver) Watch(interval time.Duration, onError func(error)) {
+	r.fingerprint = r.computeFingerprint()
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for range ticker.C {
+			fp := r.computeFingerprint()
+			if fp == r.fingerprint {
+				continue
+			}
+			r.fingerprint = fp
+			if err := r.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+}
+
+// computeFingerprint根据证书文件的名称、大小和修改时间计算指纹
+func (r *SNIResolver) computeFingerprint() string {
+	var b strings.Builder
+	for _, store := range r.stores {
+		entries, err := os.ReadDir(store.Dir())
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !strings.HasSuffix(e.Name(), certExt) && !strings.HasSuffix(e.Name(), keyExt) {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "%s/%s:%d:%d;", store.Dir(), e.Name(), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
+
+// certificateHosts返回证书包含的域名，没有SAN时使用CN
+func certificateHosts(leaf *x509.Certificate) []string {
+	hosts := leaf.DNSNames
+	if len(hosts) == 0 && leaf.Subject.CommonName != "" {
+		hosts = []string{leaf.Subject.CommonName}
+	}
+	lower := make([]string, len(hosts))
+	for i, h := range hosts {
+		lower[i] = strings.ToLower(h)
+	}
+	return lower
+}
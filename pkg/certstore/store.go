@@ -0,0 +1,354 @@
+package certstore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/pkg/secret"
+)
+
+const (
+	certExt = ".crt"
+	keyExt  = ".key"
+)
+
+var nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Store 基于目录的证书存储，证书保存为<name>.crt，私钥保存为<name>.key
+// 通过Save保存的私钥使用主密钥加密，手动放置的明文私钥也可以读取
+// 读取时按文件修改时间缓存解析结果，文件变化后自动重新加载
+type Store struct {
+	dir   string
+	mu    sync.Mutex
+	cache map[string]*entry
+}
+
+type entry struct {
+	modTime time.Time
+	size    int64
+	value   any
+}
+
+// NewStore创建证书存储，目录不存在时自动创建
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, cache: make(map[string]*entry)}, nil
+}
+
+// Dir返回证书存储目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save校验并保存证书（可包含证书链或CA证书包）和可选的私钥
+func (s *Store) Save(name string, certPEM, keyPEM []byte) ([]*x509.Certificate, error) {
+	staged, err := s.Stage(name, certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return staged.Certificates, staged.Commit()
+}
+
+// Staged 已校验并写入临时文件、尚未生效的证书，Commit后替换正式文件，Discard则放弃
+type Staged struct {
+	Certificates []*x509.Certificate
+
+	certTmp, certPath string
+	keyTmp, keyPath   string
+}
+
+// Stage校验证书和可选的私钥并写入临时文件，不影响当前使用的证书，
+// 调用方在数据库等其他操作成功后再调用Commit使其生效
+func (s *Store) Stage(name string, certPEM, keyPEM []byte) (*Staged, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid certificate name %q", name)
+	}
+	certs, err := ParseCertificates(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyPEM) > 0 {
+		if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			return nil, fmt.Errorf("certificate and private key do not match: %v", err)
+		}
+	}
+
+	st := &Staged{
+		Certificates: certs,
+		certPath:     filepath.Join(s.dir, name+certExt),
+		keyPath:      filepath.Join(s.dir, name+keyExt),
+	}
+	if st.certTmp, err = writeTemp(s.dir, name+certExt, certPEM, 0o644); err != nil {
+		return nil, err
+	}
+	if len(keyPEM) > 0 {
+		sealed, err := secret.Seal(keyPEM)
+		if err != nil {
+			st.Discard()
+			return nil, err
+		}
+		if st.keyTmp, err = writeTemp(s.dir, name+keyExt, sealed, 0o600); err != nil {
+			st.Discard()
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// Commit用临时文件替换正式的证书和私钥文件，没有私钥时删除旧的私钥文件
+func (st *Staged) Commit() error {
+	if st.keyTmp != "" {
+		if err := os.Rename(st.keyTmp, st.keyPath); err != nil {
+			st.Discard()
+			return err
+		}
+		st.keyTmp = ""
+	} else if err := os.Remove(st.keyPath); err != nil && !os.IsNotExist(err) {
+		st.Discard()
+		return err
+	}
+	if err := os.Rename(st.certTmp, st.certPath); err != nil {
+		st.Discard()
+		return err
+	}
+	st.certTmp = ""
+	return nil
+}
+
+// Discard删除尚未生效的临时文件
+func (st *Staged) Discard() {
+	for _, tmp := range []string{st.certTmp, st.keyTmp} {
+		if tmp != "" {
+			os.Remove(tmp)
+		}
+	}
+	st.certTmp, st.keyTmp = "", ""
+}
+
+// Remove删除证书及私钥
+func (s *Store) Remove(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid certificate name %q", name)
+	}
+	for _, ext := range []string{certExt, keyExt} {
+		if err := os.Remove(filepath.Join(s.dir, name+ext)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResealKeys使用当前主密钥重新加密所有私钥文件，明文私钥会被加密，返回重新加密的文件数
+func (s *Store) ResealKeys() (int, error) {
+	names, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, name := range names {
+		path := filepath.Join(s.dir, name+keyExt)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		var resealed []byte
+		changed := true
+		if secret.IsSealed(data) {
+			resealed, changed, err = secret.Reseal(data)
+		} else {
+			resealed, err = secret.Seal(data)
+		}
+		if err != nil {
+			return count, fmt.Errorf("private key of certificate %q: %v", name, err)
+		}
+		if !changed {
+			continue
+		}
+		tmp, err := writeTemp(s.dir, name+keyExt, resealed, 0o600)
+		if err != nil {
+			return count, err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// List返回存储中所有证书的名称
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), certExt)
+		if e.IsDir() || name == e.Name() || !nameRegexp.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// HasKey判断证书是否带有私钥
+func (s *Store) HasKey(name string) bool {
+	_, err := os.Stat(filepath.Join(s.dir, name+keyExt))
+	return err == nil
+}
+
+// Leaf加载证书文件中的第一个证书
+func (s *Store) Leaf(name string) (*x509.Certificate, error) {
+	v, err := s.load(name, "leaf", func(certPEM []byte) (any, error) {
+		certs, err := ParseCertificates(certPEM)
+		if err != nil {
+			return nil, err
+		}
+		return certs[0], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*x509.Certificate), nil
+}
+
+// Certificate加载证书及私钥，用于TLS握手
+func (s *Store) Certificate(name string) (*tls.Certificate, error) {
+	v, err := s.load(name, "pair", func(certPEM []byte) (any, error) {
+		keyPEM, err := os.ReadFile(filepath.Join(s.dir, name+keyExt))
+		if err != nil {
+			return nil, fmt.Errorf("private key of certificate %q not found", name)
+		}
+		if keyPEM, err = secret.Open(keyPEM); err != nil {
+			return nil, fmt.Errorf("decrypt private key of certificate %q: %v", name, err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		return &cert, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*tls.Certificate), nil
+}
+
+// CertPool加载CA证书包
+func (s *Store) CertPool(name string) (*x509.CertPool, error) {
+	v, err := s.load(name, "pool", func(certPEM []byte) (any, error) {
+		certs, err := ParseCertificates(certPEM)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		for _, cert := range certs {
+			pool.AddCert(cert)
+		}
+		return pool, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*x509.CertPool), nil
+}
+
+// load读取证书文件，文件未变化时返回缓存的解析结果
+func (s *Store) load(name, kind string, parse func(certPEM []byte) (any, error)) (any, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid certificate name %q", name)
+	}
+	path := filepath.Join(s.dir, name+certExt)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("certificate %q not found", name)
+	}
+	modTime := info.ModTime()
+	if keyInfo, err := os.Stat(filepath.Join(s.dir, name+keyExt)); err == nil && keyInfo.ModTime().After(modTime) {
+		modTime = keyInfo.ModTime()
+	}
+
+	cacheKey := kind + ":" + name
+	s.mu.Lock()
+	e, ok := s.cache[cacheKey]
+	s.mu.Unlock()
+	if ok && e.modTime.Equal(modTime) && e.size == info.Size() {
+		return e.value, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[cacheKey] = &entry{modTime: modTime, size: info.Size(), value: v}
+	s.mu.Unlock()
+	return v, nil
+}
+
+// ParseCertificates解析PEM格式的证书（可包含多个）
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// writeTemp在目录中创建临时文件并写入内容，临时文件不会被List当作证书
+func writeTemp(dir, name string, data []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), perm)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
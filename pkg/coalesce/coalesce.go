@@ -0,0 +1,61 @@
+package coalesce
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPanicked 执行调用的第一个调用者发生panic时，等待者得到的错误
+var ErrPanicked = errors.New("coalesce: coalesced call panicked")
+
+// Group 合并相同键的并发调用，同一时间只有第一个调用者执行，其他调用者等待并共享结果
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+type call[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Do执行fn，相同键的调用正在进行时等待其结果，等待超过wait后自行执行fn
+// shared表示结果来自其他调用者
+func (g *Group[T]) Do(key string, wait time.Duration, fn func() (T, error)) (val T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-c.done:
+			return c.val, true, c.err
+		case <-timer.C:
+			val, err = fn()
+			return val, false, err
+		}
+	}
+	c := &call[T]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	// fn发生panic时也要释放等待者，并让等待者得到ErrPanicked而不是零值结果
+	returned := false
+	defer func() {
+		if !returned {
+			c.err = ErrPanicked
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn()
+	returned = true
+	return c.val, false, c.err
+}
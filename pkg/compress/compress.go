@@ -0,0 +1,171 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 支持的内容编码
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+	Brotli  = "br"
+	Zstd    = "zstd"
+)
+
+// ErrTooLarge 解码后的内容超出限制
+var ErrTooLarge = errors.New("decoded body too large")
+
+// Supported判断是否支持内容编码，encoding可以是逗号分隔的多个编码
+func Supported(encoding string) bool {
+	for _, enc := range parseEncodings(encoding) {
+		switch enc {
+		case Gzip, Deflate, Brotli, Zstd, "identity":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewReader返回解码读取器，多个编码按Content-Encoding中相反的顺序解码
+func NewReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	rc := io.NopCloser(r)
+	encodings := parseEncodings(encoding)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		if rc, err = newReader(encodings[i], rc); err != nil {
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+func newReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Deflate:
+		return zlib.NewReader(r)
+	case Brotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case Zstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case "identity":
+		return io.NopCloser(r), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// NewWriter返回编码写入器，level为0时使用各编码的默认压缩级别
+func NewWriter(encoding string, w io.Writer, level int) (io.WriteCloser, error) {
+	switch encoding {
+	case Gzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case Deflate:
+		if level == 0 {
+			level = zlib.DefaultCompression
+		}
+		return zlib.NewWriterLevel(w, level)
+	case Brotli:
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(w, level), nil
+	case Zstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		return zstd.NewWriter(w, opts...)
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// Decode解码内容，limit大于0时解码后的内容超过limit返回ErrTooLarge
+func Decode(encoding string, data []byte, limit int64) ([]byte, error) {
+	r, err := NewReader(encoding, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadAll(r, limit)
+}
+
+// ReadAll读取全部内容，limit大于0时超过limit返回ErrTooLarge，用于防止压缩炸弹
+func ReadAll(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+// Negotiate根据Accept-Encoding选择编码，preferred为网关支持的编码及优先顺序
+// 客户端权重相同时按preferred的顺序选择，没有可用的编码时返回空字符串
+func Negotiate(acceptEncoding string, preferred []string) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = parsed
+			}
+		}
+		weights[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range preferred {
+		q, ok := weights[enc]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// parseEncodings拆分Content-Encoding，x-gzip按gzip处理
+func parseEncodings(encoding string) []string {
+	var list []string
+	for _, enc := range strings.Split(encoding, ",") {
+		enc = strings.ToLower(strings.TrimSpace(enc))
+		if enc == "x-gzip" {
+			enc = Gzip
+		}
+		if enc != "" {
+			list = append(list, enc)
+		}
+	}
+	return list
+}
@@ -0,0 +1,184 @@
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 默认允许的请求方法
+var defaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// Options CORS策略配置
+//
+// AllowOrigins支持三种写法：
+//   - 精确匹配：https://app.example.com，*表示允许任意来源
+//   - 通配子域名：https://*.example.com
+//   - 正则表达式：以~开头，如~^https://app[0-9]+\.example\.com$
+type Options struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string // 为空或包含*时允许预检请求中声明的所有请求头
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int // 预检结果缓存时间（秒）
+}
+
+// Policy 编译后的CORS策略
+type Policy struct {
+	opts     Options
+	any      bool
+	exact    map[string]bool
+	patterns []*regexp.Regexp
+	methods  map[string]bool
+	headers  map[string]bool
+}
+
+// Compile校验并编译CORS策略
+func Compile(opts Options) (*Policy, error) {
+	p := &Policy{
+		opts:    opts,
+		exact:   make(map[string]bool),
+		methods: make(map[string]bool),
+	}
+	for _, origin := range opts.AllowOrigins {
+		switch {
+		case origin == "*":
+			p.any = true
+		case strings.HasPrefix(origin, "~"):
+			re, err := regexp.Compile(origin[1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid origin regexp %q: %v", origin, err)
+			}
+			p.patterns = append(p.patterns, re)
+		case strings.Contains(origin, "*"):
+			if !strings.Contains(origin, "://*.") || strings.Count(origin, "*") != 1 {
+				return nil, fmt.Errorf("invalid wildcard origin %q, expected scheme://*.domain", origin)
+			}
+			expr := "^" + strings.Replace(regexp.QuoteMeta(strings.ToLower(origin)), `\*`, `[a-z0-9-]+(\.[a-z0-9-]+)*`, 1) + "$"
+			p.patterns = append(p.patterns, regexp.MustCompile(expr))
+		default:
+			p.exact[strings.ToLower(origin)] = true
+		}
+	}
+	if p.any && opts.AllowCredentials {
+		return nil, fmt.Errorf("origin * cannot be used together with credentials")
+	}
+
+	methods := opts.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultMethods
+	}
+	for _, m := range methods {
+		p.methods[strings.ToUpper(m)] = true
+	}
+
+	if len(opts.AllowHeaders) > 0 {
+		p.headers = make(map[string]bool)
+		for _, h := range opts.AllowHeaders {
+			if h == "*" {
+				p.headers = nil
+				break
+			}
+			p.headers[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+	return p, nil
+}
+
+// AllowOrigin判断来源是否被允许
+func (p *Policy) AllowOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if p.any {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if p.exact[lower] {
+		return true
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(origin) || re.MatchString(lower) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPreflight判断是否为CORS预检请求
+func IsPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// Preflight校验预检请求并设置响应头，不允许时返回false
+func (p *Policy) Preflight(r *http.Request, h http.Header) bool {
+	origin := r.Header.Get("Origin")
+	if !p.AllowOrigin(origin) {
+		return false
+	}
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !p.methods[method] {
+		return false
+	}
+
+	var requested []string
+	for _, v := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				requested = append(requested, name)
+			}
+		}
+	}
+	if p.headers != nil {
+		for _, name := range requested {
+			if !p.headers[http.CanonicalHeaderKey(name)] {
+				return false
+			}
+		}
+	}
+
+	p.setOrigin(h, origin)
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	allowMethods := p.opts.AllowMethods
+	if len(allowMethods) == 0 {
+		allowMethods = defaultMethods
+	}
+	h.Set("Access-Control-Allow-Methods", strings.Join(allowMethods, ", "))
+	if len(requested) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if p.opts.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(p.opts.MaxAge))
+	}
+	return true
+}
+
+// Actual为实际请求设置CORS响应头，来源不被允许时不设置
+func (p *Policy) Actual(r *http.Request, h http.Header) {
+	origin := r.Header.Get("Origin")
+	if !p.AllowOrigin(origin) {
+		return
+	}
+	p.setOrigin(h, origin)
+	if len(p.opts.ExposeHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(p.opts.ExposeHeaders, ", "))
+	}
+}
+
+func (p *Policy) setOrigin(h http.Header, origin string) {
+	if p.any {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Add("Vary", "Origin")
+	}
+	if p.opts.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
@@ -0,0 +1,248 @@
+package httpcache
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ValidatorRetention 带有ETag或Last-Modified的条目过期后继续保留的时间，用于向上游发起条件请求
+const ValidatorRetention = time.Hour
+
+// 允许缓存的响应状态码
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// Entry 缓存的响应
+type Entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Vary列出的请求头及缓存时请求中的值，只有请求头的值一致时才能使用该条目
+	Vary                 map[string]string
+	StoredAt             time.Time
+	FreshUntil           time.Time
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+}
+
+// Storable判断响应是否允许被网关缓存
+// authorized表示请求携带了由上游校验的Authorization，此时只有响应明确允许共享缓存时才能缓存
+func Storable(status int, header http.Header, authorized bool) bool {
+	if !cacheableStatus[status] {
+		return false
+	}
+	cc := ParseCacheControl(header)
+	if cc.Has("no-store") || cc.Has("private") {
+		return false
+	}
+	// 共享缓存不能保存会话Cookie
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, name := range varyHeaders(header) {
+		if name == "*" {
+			return false
+		}
+	}
+	if authorized && !cc.Has("public") && !cc.Has("s-maxage") && !cc.Has("must-revalidate") {
+		return false
+	}
+	return true
+}
+
+// Freshness根据响应头计算新鲜期，优先使用s-maxage，其次是max-age和Expires
+// 响应没有声明新鲜期时返回false，网关不做启发式缓存
+func Freshness(header http.Header, now time.Time) (time.Duration, bool) {
+	cc := ParseCacheControl(header)
+	if cc.Has("no-cache") {
+		return 0, true
+	}
+	if ttl, ok := cc.Seconds("s-maxage"); ok {
+		return ttl, true
+	}
+	if ttl, ok := cc.Seconds("max-age"); ok {
+		return ttl, true
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			// 无效的Expires表示已经过期
+			return 0, true
+		}
+		date := now
+		if d, err := http.ParseTime(header.Get("Date")); err == nil {
+			date = d
+		}
+		if ttl := t.Sub(date); ttl > 0 {
+			return ttl, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// NewEntry根据响应创建缓存条目，stale-while-revalidate和stale-if-error取自响应头
+func NewEntry(req *http.Request, status int, header http.Header, body []byte, ttl time.Duration, now time.Time) *Entry {
+	e := &Entry{
+		Status:     status,
+		Header:     header.Clone(),
+		Body:       body,
+		StoredAt:   now,
+		FreshUntil: now.Add(ttl),
+	}
+	e.setStale()
+	if names := varyHeaders(header); len(names) > 0 {
+		e.Vary = make(map[string]string, len(names))
+		for _, name := range names {
+			e.Vary[name] = strings.Join(req.Header.Values(name), ",")
+		}
+	}
+	return e
+}
+
+// Revalidated返回上游以304确认后的新条目，304响应中的头部会更新到条目中
+// 新条目的新鲜期需要由调用方根据合并后的响应头重新设置
+func (e *Entry) Revalidated(header http.Header, now time.Time) *Entry {
+	updated := *e
+	updated.Header = e.Header.Clone()
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+		updated.Header[name] = values
+	}
+	updated.StoredAt = now
+	updated.FreshUntil = now
+	updated.setStale()
+	return &updated
+}
+
+// setStale根据Cache-Control设置过期后仍可使用的时间
+func (e *Entry) setStale() {
+	cc := ParseCacheControl(e.Header)
+	e.StaleWhileRevalidate, _ = cc.Seconds("stale-while-revalidate")
+	e.StaleIfError, _ = cc.Seconds("stale-if-error")
+	// 响应声明must-revalidate时不能在过期后使用
+	if cc.Has("must-revalidate") || cc.Has("proxy-revalidate") {
+		e.StaleWhileRevalidate = 0
+		e.StaleIfError = 0
+	}
+}
+
+// Size返回条目的大致大小
+func (e *Entry) Size() int {
+	size := len(e.Body)
+	for name, values := range e.Header {
+		for _, v := range values {
+			size += len(name) + len(v)
+		}
+	}
+	return size
+}
+
+// Fresh判断条目是否仍在新鲜期内
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.FreshUntil)
+}
+
+// Age返回条目自存储以来的时间
+func (e *Entry) Age(now time.Time) time.Duration {
+	if age := now.Sub(e.StoredAt); age > 0 {
+		return age
+	}
+	return 0
+}
+
+// StaleWithin判断过期的条目是否在允许使用的时间窗口内
+func (e *Entry) StaleWithin(window time.Duration, now time.Time) bool {
+	return now.Before(e.FreshUntil.Add(window))
+}
+
+// HasValidators判断条目是否可以通过条件请求重新验证
+func (e *Entry) HasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// ExpireAt返回条目可以从存储中删除的时间
+func (e *Entry) ExpireAt() time.Time {
+	keep := max(e.StaleWhileRevalidate, e.StaleIfError)
+	if e.HasValidators() {
+		keep = max(keep, ValidatorRetention)
+	}
+	return e.FreshUntil.Add(keep)
+}
+
+// MatchesVary判断请求与缓存时的请求在Vary列出的请求头上是否一致
+func (e *Entry) MatchesVary(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if strings.Join(req.Header.Values(name), ",") != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Conditional为发往上游的请求设置条件请求头
+func (e *Entry) Conditional(req *http.Request) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// NotModified判断客户端的条件请求是否命中条目，命中时可以直接返回304
+func (e *Entry) NotModified(req *http.Request) bool {
+	if e.Status != http.StatusOK {
+		return false
+	}
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := e.Header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakEqual(candidate, etag) {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		modified, err := http.ParseTime(e.Header.Get("Last-Modified"))
+		return err == nil && !modified.After(since)
+	}
+	return false
+}
+
+// weakEqual按弱比较规则比较两个ETag
+func weakEqual(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// varyHeaders返回响应Vary列出的请求头
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
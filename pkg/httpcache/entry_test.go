@@ -0,0 +1,110 @@
+package httpcache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func header(kv ...string) http.Header {
+	h := make(http.Header)
+	for i := 0; i < len(kv); i += 2 {
+		h.Add(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	date := now.Add(-10 * time.Second).Format(http.TimeFormat)
+	tests := []struct {
+		name   string
+		header http.Header
+		ttl    time.Duration
+		ok     bool
+	}{
+		{"max-age", header("Cache-Control", "max-age=60"), time.Minute, true},
+		{"s-maxage wins", header("Cache-Control", "max-age=60, s-maxage=300"), 5 * time.Minute, true},
+		{"no-cache", header("Cache-Control", "no-cache, max-age=60"), 0, true},
+		{"invalid max-age", header("Cache-Control", "max-age=abc"), 0, false},
+		{"expires relative to date", header("Date", date, "Expires", now.Add(50*time.Second).Format(http.TimeFormat)), time.Minute, true},
+		{"expires in the past", header("Expires", now.Add(-time.Minute).Format(http.TimeFormat)), 0, true},
+		{"invalid expires", header("Expires", "0"), 0, true},
+		{"max-age over expires", header("Cache-Control", "max-age=5", "Expires", now.Add(time.Hour).Format(http.TimeFormat)), 5 * time.Second, true},
+		{"no freshness", header("Content-Type", "text/plain"), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, ok := Freshness(tt.header, now)
+			if ttl != tt.ttl || ok != tt.ok {
+				t.Fatalf("Freshness() = %v, %v, want %v, %v", ttl, ok, tt.ttl, tt.ok)
+			}
+		})
+	}
+}
+
+func TestStorable(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		authorized bool
+		want       bool
+	}{
+		{"ok", http.StatusOK, header("Cache-Control", "max-age=60"), false, true},
+		{"not found", http.StatusNotFound, header(), false, true},
+		{"server error", http.StatusInternalServerError, header("Cache-Control", "max-age=60"), false, false},
+		{"partial content", http.StatusPartialContent, header("Cache-Control", "max-age=60"), false, false},
+		{"no-store", http.StatusOK, header("Cache-Control", "no-store"), false, false},
+		{"private", http.StatusOK, header("Cache-Control", "private, max-age=60"), false, false},
+		{"set-cookie", http.StatusOK, header("Cache-Control", "max-age=60", "Set-Cookie", "id=1"), false, false},
+		{"vary star", http.StatusOK, header("Cache-Control", "max-age=60", "Vary", "Accept, *"), false, false},
+		{"authorized", http.StatusOK, header("Cache-Control", "max-age=60"), true, false},
+		{"authorized public", http.StatusOK, header("Cache-Control", "public, max-age=60"), true, true},
+		{"authorized s-maxage", http.StatusOK, header("Cache-Control", "s-maxage=60"), true, true},
+		{"authorized must-revalidate", http.StatusOK, header("Cache-Control", "must-revalidate, max-age=60"), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Storable(tt.status, tt.header, tt.authorized); got != tt.want {
+				t.Fatalf("Storable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := &Entry{
+		Status: http.StatusOK,
+		Header: header("ETag", `W/"v1"`, "Last-Modified", modified.Format(http.TimeFormat)),
+	}
+	tests := []struct {
+		name   string
+		entry  *Entry
+		header http.Header
+		want   bool
+	}{
+		{"etag", entry, header("If-None-Match", `W/"v1"`), true},
+		{"weak comparison", entry, header("If-None-Match", `"v1"`), true},
+		{"etag list", entry, header("If-None-Match", `"v0", W/"v1"`), true},
+		{"star", entry, header("If-None-Match", "*"), true},
+		{"etag mismatch", entry, header("If-None-Match", `"v2"`), false},
+		// If-None-Match存在时忽略If-Modified-Since
+		{"etag mismatch with date", entry, header("If-None-Match", `"v2"`, "If-Modified-Since", modified.Format(http.TimeFormat)), false},
+		{"not modified since", entry, header("If-Modified-Since", modified.Format(http.TimeFormat)), true},
+		{"modified since", entry, header("If-Modified-Since", modified.Add(-time.Second).Format(http.TimeFormat)), false},
+		{"invalid date", entry, header("If-Modified-Since", "yesterday"), false},
+		{"unconditional", entry, header(), false},
+		{"not 200", &Entry{Status: http.StatusNotFound, Header: entry.Header}, header("If-None-Match", `W/"v1"`), false},
+		{"no etag", &Entry{Status: http.StatusOK, Header: header()}, header("If-None-Match", "*"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &http.Request{Header: tt.header}
+			if got := tt.entry.NotModified(req); got != tt.want {
+				t.Fatalf("NotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
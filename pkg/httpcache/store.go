@@ -0,0 +1,252 @@
+package httpcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/pkg/secret"
+	"api-gateway/utils"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// pebble中缓存条目的键前缀
+const keyPrefix = "cache_"
+
+// Options 缓存存储配置
+type Options struct {
+	MemoryBytes     int // 内存中最多保存的条目总大小
+	MemoryEntrySize int // 超过该大小的条目只保存在pebble中
+}
+
+// Store 两级响应缓存，较小的热点条目保存在内存LRU中，所有条目加密后持久化到pebble
+type Store struct {
+	db   *pebble.DB
+	opts Options
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+	used  int
+}
+
+// record pebble中保存的条目及删除时间
+type record struct {
+	Entry    *Entry
+	ExpireAt time.Time
+}
+
+type memoryItem struct {
+	key    string
+	record record
+	size   int
+}
+
+// NewStore创建缓存存储
+func NewStore(db *pebble.DB, opts Options) *Store {
+	return &Store{
+		db:    db,
+		opts:  opts,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get返回缓存的条目，不存在或已过保留期时返回nil
+func (s *Store) Get(key string) (*Entry, error) {
+	id := storeKey(key)
+	now := time.Now()
+	if rec, ok := s.getMemory(id); ok {
+		if now.Before(rec.ExpireAt) {
+			return rec.Entry, nil
+		}
+		s.removeMemory(id)
+	}
+
+	value, closer, err := s.db.Get([]byte(id))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rec, err := decodeRecord(value)
+	closer.Close()
+	if err != nil || !now.Before(rec.ExpireAt) {
+		// 无法解密（如主密钥已删除）的条目与过期条目一样直接丢弃
+		return nil, s.db.Delete([]byte(id), pebble.NoSync)
+	}
+	s.setMemory(id, rec)
+	return rec.Entry, nil
+}
+
+// Set保存条目，条目在ExpireAt之后删除
+func (s *Store) Set(key string, entry *Entry) error {
+	id := storeKey(key)
+	rec := record{Entry: entry, ExpireAt: entry.ExpireAt()}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	sealed, err := secret.Seal(data)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Set([]byte(id), sealed, pebble.NoSync); err != nil {
+		return err
+	}
+	s.setMemory(id, rec)
+	return nil
+}
+
+// GetVariant返回与请求的Vary请求头一致的条目，没有一致的条目时返回nil
+// 主键保存最近写入的条目，主键条目与请求不一致时按它的Vary列表和请求中的取值查找二级键
+func (s *Store) GetVariant(key string, req *http.Request) (*Entry, error) {
+	entry, err := s.Get(key)
+	if entry == nil || entry.MatchesVary(req) {
+		return entry, err
+	}
+	values := make(map[string]string, len(entry.Vary))
+	for name := range entry.Vary {
+		values[name] = strings.Join(req.Header.Values(name), ",")
+	}
+	entry, err = s.Get(VariantKey(key, values))
+	if entry != nil && !entry.MatchesVary(req) {
+		return nil, err
+	}
+	return entry, err
+}
+
+// SetVariant保存条目，带Vary的条目同时按Vary请求头的取值保存到二级键，不同取值的变体互不覆盖
+func (s *Store) SetVariant(key string, entry *Entry) error {
+	if err := s.Set(key, entry); err != nil {
+		return err
+	}
+	if len(entry.Vary) == 0 {
+		return nil
+	}
+	return s.Set(VariantKey(key, entry.Vary), entry)
+}
+
+// VariantKey返回Vary请求头取值对应的二级缓存键
+func VariantKey(key string, vary map[string]string) string {
+	if len(vary) == 0 {
+		return key
+	}
+	names := make([]string, 0, len(vary))
+	for name := range vary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(key)
+	b.WriteString("\nvary")
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(vary[name])
+	}
+	return b.String()
+}
+
+// Purge删除pebble中已过保留期的条目，返回删除的数量
+func (s *Store) Purge() (int, error) {
+	iter, err := s.db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(keyPrefix),
+		UpperBound: utils.PrefixUpperBound([]byte(keyPrefix)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	now := time.Now()
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		rec, err := decodeRecord(iter.Value())
+		if err != nil || !now.Before(rec.ExpireAt) {
+			batch.Delete(append([]byte(nil), iter.Key()...), nil)
+			count++
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+	return count, batch.Commit(pebble.NoSync)
+}
+
+func (s *Store) getMemory(id string) (record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[id]
+	if !ok {
+		return record{}, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*memoryItem).record, true
+}
+
+// setMemory把较小的条目放入内存，超出容量时淘汰最久未使用的条目
+func (s *Store) setMemory(id string, rec record) {
+	size := rec.Entry.Size()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[id]; ok {
+		s.remove(el)
+	}
+	if size > s.opts.MemoryEntrySize || size > s.opts.MemoryBytes {
+		return
+	}
+	s.items[id] = s.order.PushFront(&memoryItem{key: id, record: rec, size: size})
+	s.used += size
+	for s.used > s.opts.MemoryBytes {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *Store) removeMemory(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[id]; ok {
+		s.remove(el)
+	}
+}
+
+func (s *Store) remove(el *list.Element) {
+	item := el.Value.(*memoryItem)
+	s.order.Remove(el)
+	delete(s.items, item.key)
+	s.used -= item.size
+}
+
+// storeKey对缓存键做哈希，避免查询参数等原始内容以明文出现在pebble的键中
+func storeKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return keyPrefix + hex.EncodeToString(sum[:])
+}
+
+func decodeRecord(value []byte) (record, error) {
+	data, err := secret.Open(value)
+	if err != nil {
+		return record{}, err
+	}
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return record{}, err
+	}
+	if rec.Entry == nil {
+		return record{}, errors.New("empty cache entry")
+	}
+	return rec, nil
+}
@@ -0,0 +1,104 @@
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"api-gateway/pkg/secret"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	if err := secret.SetKeys([][]byte{bytes.Repeat([]byte{1}, 32)}); err != nil {
+		t.Fatal(err)
+	}
+	db, err := pebble.Open("", &pebble.Options{FS: vfs.NewMem()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// 内存容量为0，所有读取都经过pebble
+	return NewStore(db, Options{})
+}
+
+func TestVariants(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now()
+	request := func(language string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+		if language != "" {
+			req.Header.Set("Accept-Language", language)
+		}
+		return req
+	}
+	respHeader := header("Cache-Control", "max-age=60", "Vary", "accept-language")
+	for _, language := range []string{"en", "fr", "de"} {
+		entry := NewEntry(request(language), http.StatusOK, respHeader, []byte(language), time.Minute, now)
+		if err := store.SetVariant("key", entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		language string
+		want     string // 为空表示没有一致的条目
+	}{
+		{"latest variant", "de", "de"},
+		{"earlier variant", "en", "en"},
+		{"other variant", "fr", "fr"},
+		{"unknown variant", "ja", ""},
+		{"missing header", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := store.GetVariant("key", request(tt.language))
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.want == "" && entry != nil:
+				t.Fatalf("GetVariant() = %q, want nil", entry.Body)
+			case tt.want != "" && (entry == nil || string(entry.Body) != tt.want):
+				t.Fatalf("GetVariant() = %v, want %q", entry, tt.want)
+			}
+		})
+	}
+
+	if entry, err := store.GetVariant("other", request("en")); entry != nil || err != nil {
+		t.Fatalf("GetVariant() of missing key = %v, %v", entry, err)
+	}
+}
+
+func TestVariantWithoutVary(t *testing.T) {
+	store := newTestStore(t)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	entry := NewEntry(req, http.StatusOK, header("Cache-Control", "max-age=60"), []byte("body"), time.Minute, time.Now())
+	if err := store.SetVariant("key", entry); err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Language", "en")
+	got, err := store.GetVariant("key", req)
+	if err != nil || got == nil || string(got.Body) != "body" {
+		t.Fatalf("GetVariant() = %v, %v", got, err)
+	}
+}
+
+func TestVariantKey(t *testing.T) {
+	a := VariantKey("key", map[string]string{"Accept": "json", "Accept-Language": "en"})
+	b := VariantKey("key", map[string]string{"Accept-Language": "en", "Accept": "json"})
+	if a != b {
+		t.Fatalf("VariantKey() depends on map order: %q != %q", a, b)
+	}
+	if VariantKey("key", nil) != "key" {
+		t.Fatal("VariantKey() without vary should return the key")
+	}
+	if VariantKey("key", map[string]string{"Accept": "en"}) == VariantKey("key", map[string]string{"Accept": "fr"}) {
+		t.Fatal("VariantKey() should differ by value")
+	}
+}
@@ -0,0 +1,55 @@
+package ipacl
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParsePrefixes解析CIDR列表，单个IP按/32或/128处理
+func ParsePrefixes(list []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ip %q", s)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q", s)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Contains判断IP是否在任一网段中，IPv4映射的IPv6地址按IPv4处理
+func Contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match根据允许和拒绝列表判断IP是否允许访问：命中拒绝列表时拒绝，
+// 允许列表不为空且未命中时拒绝，其余情况允许
+func Match(allow, deny []netip.Prefix, addr netip.Addr) bool {
+	if Contains(deny, addr) {
+		return false
+	}
+	if len(allow) > 0 && !Contains(allow, addr) {
+		return false
+	}
+	return true
+}
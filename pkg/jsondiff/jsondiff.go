@@ -0,0 +1,81 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Change 一处字段变更，Before或After为nil表示字段新增或删除
+type Change struct {
+	Path   string
+	Before any
+	After  any
+}
+
+// Diff比较两个JSON文档，返回按路径排序的字段变更，对象逐个字段比较，数组长度不同时整体比较
+func Diff(before, after []byte) ([]Change, error) {
+	b, err := decode(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := decode(after)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	diff("", b, a, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func decode(data []byte) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func diff(path string, before, after any, changes *[]Change) {
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+		for k, bv := range b {
+			diff(join(path, k), bv, a[k], changes)
+		}
+		for k, av := range a {
+			if _, ok := b[k]; !ok {
+				diff(join(path, k), nil, av, changes)
+			}
+		}
+		return
+	case []any:
+		a, ok := after.([]any)
+		if !ok || len(a) != len(b) {
+			break
+		}
+		for i := range b {
+			diff(join(path, strconv.Itoa(i)), b[i], a[i], changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Before: before, After: after})
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,278 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+)
+
+// 算法使用的摘要
+var algorithmHashes = map[string]crypto.Hash{
+	HS256: crypto.SHA256, HS384: crypto.SHA384, HS512: crypto.SHA512,
+	RS256: crypto.SHA256, RS384: crypto.SHA384, RS512: crypto.SHA512,
+	ES256: crypto.SHA256, ES384: crypto.SHA384, ES512: crypto.SHA512,
+}
+
+// 校验失败的原因，不区分具体细节，避免向客户端泄露校验过程
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+	ErrClaims    = errors.New("invalid token claims")
+)
+
+// Options JWT校验配置
+//
+// Secret用于HS*算法，PublicKey为PEM格式的RSA或ECDSA公钥（或证书），用于RS*和ES*算法，
+// Algorithms为空时根据密钥类型选择：Secret为HS256，RSA公钥为RS256，ECDSA公钥按曲线选择ES*
+type Options struct {
+	Issuer     string   // 要求的iss声明，为空不校验
+	Audience   string   // 要求的aud声明，为空不校验
+	Algorithms []string // 允许的签名算法
+	Secret     string
+	PublicKey  string
+	ClockSkew  time.Duration // exp和nbf允许的时钟偏差
+}
+
+// Verifier 编译后的JWT校验器
+type Verifier struct {
+	opts       Options
+	algorithms map[string]bool
+	secret     []byte
+	rsaKey     *rsa.PublicKey
+	ecKey      *ecdsa.PublicKey
+}
+
+// Compile校验配置并解析密钥
+func Compile(opts Options) (*Verifier, error) {
+	v := &Verifier{opts: opts, algorithms: make(map[string]bool)}
+	if opts.Secret != "" {
+		v.secret = []byte(opts.Secret)
+	}
+	if opts.PublicKey != "" {
+		key, err := parsePublicKey(opts.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			v.rsaKey = key
+		case *ecdsa.PublicKey:
+			v.ecKey = key
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+	if v.secret == nil && v.rsaKey == nil && v.ecKey == nil {
+		return nil, errors.New("secret or public key is required")
+	}
+
+	algorithms := opts.Algorithms
+	if len(algorithms) == 0 {
+		switch {
+		case v.secret != nil:
+			algorithms = []string{HS256}
+		case v.rsaKey != nil:
+			algorithms = []string{RS256}
+		default:
+			algorithms = []string{ecAlgorithm(v.ecKey)}
+		}
+	}
+	for _, alg := range algorithms {
+		if _, ok := algorithmHashes[alg]; !ok {
+			return nil, fmt.Errorf("unsupported algorithm %q", alg)
+		}
+		switch alg[:2] {
+		case "HS":
+			if v.secret == nil {
+				return nil, fmt.Errorf("algorithm %s requires a secret", alg)
+			}
+		case "RS":
+			if v.rsaKey == nil {
+				return nil, fmt.Errorf("algorithm %s requires an RSA public key", alg)
+			}
+		case "ES":
+			if v.ecKey == nil || ecAlgorithm(v.ecKey) != alg {
+				return nil, fmt.Errorf("algorithm %s requires a matching ECDSA public key", alg)
+			}
+		}
+		v.algorithms[alg] = true
+	}
+	return v, nil
+}
+
+// Verify校验令牌的签名和exp、nbf、iss、aud声明，返回令牌中的声明，令牌必须包含exp
+func (v *Verifier) Verify(token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformed
+	}
+	if !v.algorithms[header.Alg] {
+		return nil, ErrSignature
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !v.verifySignature(header.Alg, parts[0]+"."+parts[1], sig) {
+		return nil, ErrSignature
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil || claims == nil {
+		return nil, ErrMalformed
+	}
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return nil, ErrClaims
+	}
+	if !now.Before(exp.Add(v.opts.ClockSkew)) {
+		return nil, ErrExpired
+	}
+	if raw, present := claims["nbf"]; present {
+		nbf, ok := numericDate(raw)
+		if !ok || now.Add(v.opts.ClockSkew).Before(nbf) {
+			return nil, ErrClaims
+		}
+	}
+	if v.opts.Issuer != "" && claims["iss"] != v.opts.Issuer {
+		return nil, ErrClaims
+	}
+	if v.opts.Audience != "" && !hasAudience(claims["aud"], v.opts.Audience) {
+		return nil, ErrClaims
+	}
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(alg, signingInput string, sig []byte) bool {
+	hash := algorithmHashes[alg]
+	switch alg[:2] {
+	case "HS":
+		mac := hmac.New(hash.New, v.secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(mac.Sum(nil), sig)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+	switch alg[:2] {
+	case "RS":
+		return rsa.VerifyPKCS1v15(v.rsaKey, hash, digest, sig) == nil
+	case "ES":
+		// JWS中ECDSA签名为定长的r||s
+		size := (v.ecKey.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(v.ecKey, digest, r, s)
+	}
+	return false
+}
+
+// BearerToken返回Authorization请求头中的Bearer令牌
+func BearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// numericDate解析exp、nbf等以秒为单位的时间声明
+func numericDate(v any) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*float64(time.Second))), true
+}
+
+// hasAudience判断aud声明（字符串或字符串数组）是否包含要求的受众
+func hasAudience(v any, audience string) bool {
+	switch aud := v.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		return slices.Contains(aud, any(audience))
+	}
+	return false
+}
+
+// parsePublicKey解析PEM格式的公钥或证书
+func parsePublicKey(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM public key found")
+	}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
+
+func ecAlgorithm(key *ecdsa.PublicKey) string {
+	switch key.Curve.Params().BitSize {
+	case 256:
+		return ES256
+	case 384:
+		return ES384
+	default:
+		return ES512
+	}
+}
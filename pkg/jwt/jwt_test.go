@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func sign(t *testing.T, header, payload, secret string) string {
+	t.Helper()
+	input := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v, err := Compile(Options{Issuer: "idp", Audience: "gw", Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const hs256 = `{"alg":"HS256","typ":"JWT"}`
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice","iss":"idp","aud":"gw","exp":1700000060}`)) + "."
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", sign(t, hs256, `{"sub":"alice","iss":"idp","aud":"gw","exp":1700000060}`, "secret"), nil},
+		{"audience array", sign(t, hs256, `{"sub":"alice","iss":"idp","aud":["a","gw"],"exp":1700000060}`, "secret"), nil},
+		{"wrong secret", sign(t, hs256, `{"sub":"alice","iss":"idp","aud":"gw","exp":1700000060}`, "other"), ErrSignature},
+		{"alg none", unsigned, ErrSignature},
+		{"alg not allowed", sign(t, `{"alg":"HS512"}`, `{"iss":"idp","aud":"gw","exp":1700000060}`, "secret"), ErrSignature},
+		{"expired", sign(t, hs256, `{"iss":"idp","aud":"gw","exp":1699999999}`, "secret"), ErrExpired},
+		{"missing exp", sign(t, hs256, `{"iss":"idp","aud":"gw"}`, "secret"), ErrClaims},
+		{"not yet valid", sign(t, hs256, `{"iss":"idp","aud":"gw","exp":1700000060,"nbf":1700000030}`, "secret"), ErrClaims},
+		{"wrong issuer", sign(t, hs256, `{"iss":"other","aud":"gw","exp":1700000060}`, "secret"), ErrClaims},
+		{"wrong audience", sign(t, hs256, `{"iss":"idp","aud":"other","exp":1700000060}`, "secret"), ErrClaims},
+		{"malformed", "a.b", ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(tt.token, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && claims["iss"] != "idp" {
+				t.Fatalf("Verify() claims = %v", claims)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		ok   bool
+	}{
+		{"secret", Options{Secret: "s"}, true},
+		{"no key", Options{}, false},
+		{"rsa without key", Options{Secret: "s", Algorithms: []string{RS256}}, false},
+		{"unknown algorithm", Options{Secret: "s", Algorithms: []string{"none"}}, false},
+		{"invalid public key", Options{PublicKey: "not pem"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.opts); (err == nil) != tt.ok {
+				t.Fatalf("Compile() error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// 读取PROXY协议头的超时时间
+const headerTimeout = 5 * time.Second
+
+// Listener 支持PROXY协议（v1和v2）的监听器，只有来自可信代理的连接才会解析PROXY协议头
+type Listener struct {
+	net.Listener
+	// Trusted判断连接的来源地址是否为可信代理，为nil时信任所有来源
+	Trusted func(addr net.Addr) bool
+}
+
+// Accept接收连接，PROXY协议头在首次读取或获取RemoteAddr时解析
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if l.Trusted != nil && !l.Trusted(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	return &Conn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// Conn 解析了PROXY协议头的连接，RemoteAddr返回PROXY协议头中的客户端地址
+type Conn struct {
+	net.Conn
+	reader     *bufio.Reader
+	once       sync.Once
+	remoteAddr net.Addr
+	err        error
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readHeader读取PROXY协议头，没有协议头时按普通连接处理
+func (c *Conn) readHeader() {
+	c.Conn.SetReadDeadline(time.Now().Add(headerTimeout))
+	defer c.Conn.SetReadDeadline(time.Time{})
+
+	peek, err := c.reader.Peek(len(v1Prefix))
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			c.err = err
+		}
+		return
+	}
+	switch {
+	case bytes.Equal(peek, v1Prefix):
+		c.remoteAddr, c.err = c.readV1()
+	case bytes.Equal(peek, v2Signature[:len(v1Prefix)]):
+		if sig, err := c.reader.Peek(len(v2Signature)); err == nil && bytes.Equal(sig, v2Signature) {
+			c.remoteAddr, c.err = c.readV2()
+		}
+	}
+}
+
+// readV1解析文本格式的PROXY协议头，如：PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n
+func (c *Conn) readV1() (net.Addr, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) > 107 || !strings.HasSuffix(line, "\r\n") {
+		return nil, errors.New("proxy protocol: invalid v1 header")
+	}
+	fields := strings.Fields(strings.TrimSuffix(line, "\r\n"))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("proxy protocol: invalid v1 header %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("proxy protocol: invalid v1 source %q", line)
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readV2解析二进制格式的PROXY协议头
+func (c *Conn) readV2() (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return nil, err
+	}
+	if header[12]>>4 != 2 {
+		return nil, errors.New("proxy protocol: unsupported v2 version")
+	}
+	length := int(binary.BigEndian.Uint16(header[14:16]))
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return nil, err
+	}
+
+	// LOCAL命令表示代理自身发起的连接（如健康检查），使用真实连接地址
+	if header[12]&0x0f == 0 {
+		return nil, nil
+	}
+	switch header[13] >> 4 {
+	case 1: // AF_INET
+		if length < 12 {
+			return nil, errors.New("proxy protocol: short v2 ipv4 address")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 2: // AF_INET6
+		if length < 36 {
+			return nil, errors.New("proxy protocol: short v2 ipv6 address")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,33 @@
+package quota
+
+import (
+	"fmt"
+	"time"
+)
+
+// 配额周期
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Window 当前配额周期
+type Window struct {
+	Key   string    // 周期标识，如2024-05-01或2024-05
+	Start time.Time // 周期开始时间
+	End   time.Time // 周期结束（下次重置）时间
+}
+
+// Current返回指定时区下now所在的自然日或自然月
+func Current(period string, loc *time.Location, now time.Time) (Window, error) {
+	now = now.In(loc)
+	switch period {
+	case PeriodDay:
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		return Window{Key: start.Format("2006-01-02"), Start: start, End: start.AddDate(0, 0, 1)}, nil
+	case PeriodMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return Window{Key: start.Format("2006-01"), Start: start, End: start.AddDate(0, 1, 0)}, nil
+	}
+	return Window{}, fmt.Errorf("period must be %s or %s", PeriodDay, PeriodMonth)
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore 本地内存限流存储，最多保存maxKeys个键，超出时淘汰最久未使用的键
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries *lru
+}
+
+type memoryEntry struct {
+	bucket bucket
+	window window
+}
+
+// NewMemoryStore创建本地内存限流存储
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	return &MemoryStore{entries: newLRU(maxKeys)}
+}
+
+// Allow判断请求是否允许通过
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries.get(key, func() any { return &memoryEntry{} }).(*memoryEntry)
+	now := time.Now()
+	if limit.Algorithm == SlidingWindow {
+		return entry.window.hit(limit, now), nil
+	}
+	return entry.bucket.take(limit, now), nil
+}
+
+// Len返回当前保存的键数
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries.len()
+}
+
+// lru 限制键数的LRU表，调用方负责加锁
+type lru struct {
+	maxKeys int
+	items   map[string]*list.Element
+	order   *list.List
+}
+
+type lruItem struct {
+	key   string
+	value any
+}
+
+func newLRU(maxKeys int) *lru {
+	return &lru{
+		maxKeys: maxKeys,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get获取键对应的值，不存在时使用create创建，超出容量时淘汰最久未使用的键
+func (l *lru) get(key string, create func() any) any {
+	if el, ok := l.items[key]; ok {
+		l.order.MoveToFront(el)
+		return el.Value.(*lruItem).value
+	}
+	if l.maxKeys > 0 && l.order.Len() >= l.maxKeys {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruItem).key)
+	}
+	item := &lruItem{key: key, value: create()}
+	l.items[key] = l.order.PushFront(item)
+	return item.value
+}
+
+func (l *lru) len() int {
+	return l.order.Len()
+}
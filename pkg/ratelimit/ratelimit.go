@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 限流算法
+const (
+	TokenBucket   = "token-bucket"
+	SlidingWindow = "sliding-window"
+)
+
+// Limit 限流规则，每个Period允许Rate个请求
+type Limit struct {
+	Algorithm string
+	Rate      int
+	Period    time.Duration
+	Burst     int // 令牌桶容量，为0时等于Rate，滑动窗口算法忽略
+}
+
+// Validate校验限流规则
+func (l Limit) Validate() error {
+	switch l.Algorithm {
+	case TokenBucket, SlidingWindow:
+	default:
+		return fmt.Errorf("algorithm must be %s or %s", TokenBucket, SlidingWindow)
+	}
+	if l.Rate <= 0 || l.Period <= 0 {
+		return fmt.Errorf("rate and period must be positive")
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("burst must not be negative")
+	}
+	return nil
+}
+
+// capacity返回令牌桶容量
+func (l Limit) capacity() float64 {
+	if l.Algorithm == TokenBucket && l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Rate)
+}
+
+// Result 限流结果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // 配额完全恢复（令牌桶）或当前窗口结束（滑动窗口）的时间
+	RetryAfter time.Duration // 被拒绝时需要等待的时间
+}
+
+// Store 限流状态存储
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket 令牌桶状态
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take从令牌桶中取出一个令牌
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	capacity := limit.capacity()
+	rate := float64(limit.Rate) / limit.Period.Seconds() // 每秒补充的令牌数
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.last = now
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	return result
+}
+
+// window 滑动窗口计数器，使用上一窗口计数按时间加权估算滑动窗口内的请求数
+type window struct {
+	start time.Time
+	count int
+	prev  int
+}
+
+// hit在窗口中记录一次请求
+func (w *window) hit(limit Limit, now time.Time) Result {
+	start := now.Truncate(limit.Period)
+	if !start.Equal(w.start) {
+		if start.Sub(w.start) == limit.Period {
+			w.prev = w.count
+		} else {
+			w.prev = 0
+		}
+		w.start, w.count = start, 0
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(limit.Period)
+	estimate := float64(w.prev)*weight + float64(w.count)
+	result := Result{Limit: limit.Rate, Reset: limit.Period - elapsed}
+	if estimate+1 > float64(limit.Rate) {
+		// 等待上一窗口的权重下降到足以容纳一个请求，当前窗口已满时等待下一窗口
+		result.RetryAfter = limit.Period - elapsed
+		if w.prev > 0 && w.count < limit.Rate {
+			need := estimate + 1 - float64(limit.Rate)
+			wait := time.Duration(need / float64(w.prev) * float64(limit.Period))
+			if wait < result.RetryAfter {
+				result.RetryAfter = wait
+			}
+		}
+		result.Remaining = 0
+		return result
+	}
+	w.count++
+	result.Allowed = true
+	result.Remaining = int(float64(limit.Rate) - estimate - 1)
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
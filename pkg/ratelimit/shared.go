@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"api-gateway/pkg/resp"
+)
+
+// SharedStore 基于Redis协议共享后端的集群限流存储
+//
+// 两种算法在共享后端上都使用滑动窗口计数近似实现，令牌桶的容量作为窗口内的请求上限。
+// 每个实例在本地累计请求数，达到BatchSize或距上次同步超过SyncInterval时才与后端同步一次，
+// 两次同步之间使用上次同步的全局计数加本地未同步的计数进行判断，因此限流结果是近似的。
+// 后端不可用时退回到本地限流，并在RetryInterval后重试。
+type SharedStore struct {
+	client        *resp.Client
+	fallback      Store
+	prefix        string
+	batchSize     int
+	syncInterval  time.Duration
+	retryInterval time.Duration
+	onError       func(error)
+	now           func() time.Time
+
+	mu        sync.Mutex
+	entries   *lru
+	downUntil time.Time
+}
+
+// SharedOptions 共享存储配置
+type SharedOptions struct {
+	Prefix        string        // 后端键前缀
+	BatchSize     int           // 本地累计多少个请求后同步
+	SyncInterval  time.Duration // 最长同步间隔
+	RetryInterval time.Duration // 后端不可用后重试的间隔
+	MaxKeys       int           // 本地最多保存的键数
+	OnError       func(error)   // 后端不可用、退回本地限流时调用
+}
+
+// sharedEntry 单个键的本地状态
+type sharedEntry struct {
+	mu       sync.Mutex
+	start    time.Time // 当前窗口开始时间
+	global   int64     // 上次同步时当前窗口的全局计数（包括本实例已同步的部分）
+	prev     int64     // 上一窗口的全局计数
+	pending  int64     // 本地未同步的计数
+	lastSync time.Time
+
+	// 切换窗口时旧窗口中尚未同步的计数，在下次同步时累加到旧窗口的键
+	staleStart time.Time
+	stale      int64
+}
+
+// NewSharedStore创建共享存储，fallback为后端不可用时使用的本地存储
+func NewSharedStore(client *resp.Client, fallback Store, opts SharedOptions) *SharedStore {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 10
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = 100 * time.Millisecond
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = 5 * time.Second
+	}
+	return &SharedStore{
+		client:        client,
+		fallback:      fallback,
+		prefix:        opts.Prefix,
+		batchSize:     opts.BatchSize,
+		syncInterval:  opts.SyncInterval,
+		retryInterval: opts.RetryInterval,
+		onError:       opts.OnError,
+		entries:       newLRU(opts.MaxKeys),
+		now:           time.Now,
+	}
+}
+
+// Allow判断请求是否允许通过
+func (s *SharedStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+	s.mu.Lock()
+	if now.Before(s.downUntil) {
+		s.mu.Unlock()
+		return s.fallback.Allow(ctx, key, limit)
+	}
+	entry := s.entries.get(key, func() any { return &sharedEntry{} }).(*sharedEntry)
+	s.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	start := now.Truncate(limit.Period)
+	if !start.Equal(entry.start) {
+		// 进入新窗口，需要先同步以获取其他实例的计数，旧窗口未同步的计数随这次同步写回旧窗口
+		if entry.pending > 0 {
+			entry.staleStart, entry.stale = entry.start, entry.pending
+		}
+		entry.start = start
+		entry.global, entry.prev, entry.pending = 0, 0, 0
+		entry.lastSync = time.Time{}
+	}
+	if entry.pending >= int64(s.batchSize) || now.Sub(entry.lastSync) >= s.syncInterval {
+		if err := s.sync(ctx, key, entry, limit, now); err != nil {
+			s.markDown(now, err)
+			return s.fallback.Allow(ctx, key, limit)
+		}
+	}
+
+	capacity := limit.capacity()
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(limit.Period)
+	estimate := float64(entry.prev)*weight + float64(entry.global+entry.pending)
+	result := Result{Limit: int(capacity), Reset: limit.Period - elapsed}
+	if estimate+1 > capacity {
+		result.RetryAfter = limit.Period - elapsed
+		if entry.prev > 0 && float64(entry.global+entry.pending) < capacity {
+			need := estimate + 1 - capacity
+			if wait := time.Duration(need / float64(entry.prev) * float64(limit.Period)); wait < result.RetryAfter {
+				result.RetryAfter = wait
+			}
+		}
+		return result, nil
+	}
+	entry.pending++
+	result.Allowed = true
+	result.Remaining = int(capacity - estimate - 1)
+	return result, nil
+}
+
+// sync将本地计数累加到后端，并读取当前窗口和上一窗口的全局计数
+func (s *SharedStore) sync(ctx context.Context, key string, entry *sharedEntry, limit Limit, now time.Time) error {
+	window := entry.start.UnixNano() / int64(limit.Period)
+	currKey := s.windowKey(key, window)
+	prevKey := s.windowKey(key, window-1)
+	// 计数保留两个周期，下一窗口还需要读取
+	ttl := strconv.FormatInt((2 * limit.Period).Milliseconds(), 10)
+
+	var cmds [][]string
+	if entry.stale > 0 {
+		// 先写回旧窗口，使下面读取的上一窗口计数包含这部分
+		staleKey := s.windowKey(key, entry.staleStart.UnixNano()/int64(limit.Period))
+		cmds = append(cmds,
+			[]string{"INCRBY", staleKey, strconv.FormatInt(entry.stale, 10)},
+			[]string{"PEXPIRE", staleKey, ttl},
+		)
+	}
+	n := len(cmds)
+	cmds = append(cmds,
+		[]string{"INCRBY", currKey, strconv.FormatInt(entry.pending, 10)},
+		[]string{"PEXPIRE", currKey, ttl},
+		[]string{"GET", prevKey},
+	)
+
+	replies, err := s.client.Pipeline(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		if _, err := resp.Int(replies[0]); err != nil {
+			return err
+		}
+		entry.staleStart, entry.stale = time.Time{}, 0
+	}
+	global, err := resp.Int(replies[n])
+	if err != nil {
+		return err
+	}
+	prev, err := resp.Int(replies[n+2])
+	if err != nil {
+		return err
+	}
+	entry.global, entry.prev, entry.pending = global, prev, 0
+	entry.lastSync = now
+	return nil
+}
+
+// windowKey返回指定窗口在后端的计数键
+func (s *SharedStore) windowKey(key string, window int64) string {
+	return s.prefix + key + ":" + strconv.FormatInt(window, 10)
+}
+
+func (s *SharedStore) markDown(now time.Time, err error) {
+	s.mu.Lock()
+	s.downUntil = now.Add(s.retryInterval)
+	s.mu.Unlock()
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
@@ -0,0 +1,262 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"api-gateway/pkg/resp"
+)
+
+// respServer 测试用的进程内Redis协议服务，只实现SharedStore用到的INCRBY、PEXPIRE和GET
+type respServer struct {
+	ln net.Listener
+
+	mu     sync.Mutex
+	values map[string]int64
+	cmds   [][]string
+}
+
+func newRESPServer(t *testing.T) *respServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &respServer{ln: ln, values: map[string]int64{}}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *respServer) url() string { return "redis://" + s.ln.Addr().String() }
+
+func (s *respServer) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *respServer) handle(c net.Conn) {
+	defer c.Close()
+	r, w := bufio.NewReader(c), bufio.NewWriter(c)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.cmds = append(s.cmds, args)
+		switch strings.ToUpper(args[0]) {
+		case "INCRBY":
+			n, _ := strconv.ParseInt(args[2], 10, 64)
+			s.values[args[1]] += n
+			fmt.Fprintf(w, ":%d\r\n", s.values[args[1]])
+		case "PEXPIRE":
+			w.WriteString(":1\r\n")
+		case "GET":
+			if v, ok := s.values[args[1]]; ok {
+				b := strconv.FormatInt(v, 10)
+				fmt.Fprintf(w, "$%d\r\n%s\r\n", len(b), b)
+			} else {
+				w.WriteString("$-1\r\n")
+			}
+		default:
+			fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+		}
+		s.mu.Unlock()
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expected array")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (s *respServer) value(key string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// commands返回收到的指定命令
+func (s *respServer) commands(name string) [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out [][]string
+	for _, args := range s.cmds {
+		if args[0] == name {
+			out = append(out, args)
+		}
+	}
+	return out
+}
+
+// testClock 可手动推进的时钟
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time               { return c.t }
+func (c *testClock) add(d time.Duration)          { c.t = c.t.Add(d) }
+func windowOf(t time.Time, p time.Duration) int64 { return t.UnixNano() / int64(p) }
+
+func newTestSharedStore(t *testing.T, url string, opts SharedOptions) (*SharedStore, *testClock) {
+	t.Helper()
+	client, err := resp.NewClient(url, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	store := NewSharedStore(client, NewMemoryStore(100), opts)
+	clock := &testClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store.now = clock.now
+	return store, clock
+}
+
+func TestSharedStoreBatchesSync(t *testing.T) {
+	srv := newRESPServer(t)
+	store, clock := newTestSharedStore(t, srv.url(), SharedOptions{Prefix: "rl:", BatchSize: 5, SyncInterval: time.Hour})
+	limit := Limit{Algorithm: SlidingWindow, Rate: 100, Period: time.Minute}
+
+	for i := 0; i < 10; i++ {
+		res, err := store.Allow(context.Background(), "k", limit)
+		if err != nil || !res.Allowed {
+			t.Fatalf("request %d: allowed=%v err=%v", i, res.Allowed, err)
+		}
+	}
+	// 第1个请求同步0，第6个请求同步前5个，其余请求只在本地计数
+	if got := len(srv.commands("INCRBY")); got != 2 {
+		t.Fatalf("INCRBY count = %d, want 2", got)
+	}
+	key := "rl:k:" + strconv.FormatInt(windowOf(clock.t, limit.Period), 10)
+	if got := srv.value(key); got != 5 {
+		t.Fatalf("backend count = %d, want 5", got)
+	}
+}
+
+func TestSharedStoreFlushesPendingOnRollover(t *testing.T) {
+	srv := newRESPServer(t)
+	store, clock := newTestSharedStore(t, srv.url(), SharedOptions{Prefix: "rl:", BatchSize: 100, SyncInterval: time.Hour})
+	limit := Limit{Algorithm: SlidingWindow, Rate: 100, Period: time.Minute}
+
+	oldWindow := windowOf(clock.t, limit.Period)
+	for i := 0; i < 3; i++ {
+		if _, err := store.Allow(context.Background(), "k", limit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.add(limit.Period)
+	if _, err := store.Allow(context.Background(), "k", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	oldKey := "rl:k:" + strconv.FormatInt(oldWindow, 10)
+	if got := srv.value(oldKey); got != 3 {
+		t.Fatalf("old window count = %d, want 3", got)
+	}
+	incr := srv.commands("INCRBY")
+	if len(incr) != 3 || incr[1][1] != oldKey || incr[2][1] != "rl:k:"+strconv.FormatInt(oldWindow+1, 10) {
+		t.Fatalf("unexpected INCRBY sequence %v", incr)
+	}
+	entry := store.entries.get("k", nil).(*sharedEntry)
+	if entry.prev != 3 || entry.stale != 0 {
+		t.Fatalf("entry prev=%d stale=%d, want 3 and 0", entry.prev, entry.stale)
+	}
+}
+
+func TestSharedStoreEnforcesLimitAcrossInstances(t *testing.T) {
+	srv := newRESPServer(t)
+	opts := SharedOptions{Prefix: "rl:", BatchSize: 1, SyncInterval: time.Hour}
+	a, clockA := newTestSharedStore(t, srv.url(), opts)
+	b, clockB := newTestSharedStore(t, srv.url(), opts)
+	limit := Limit{Algorithm: SlidingWindow, Rate: 4, Period: time.Minute}
+	clockA.add(time.Second)
+	clockB.add(time.Second)
+
+	allowed := 0
+	for i := 0; i < 4; i++ {
+		for _, store := range []*SharedStore{a, b} {
+			res, err := store.Allow(context.Background(), "k", limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed {
+				allowed++
+			}
+		}
+	}
+	// 每个请求都同步，两个实例最多各多放行一个尚未同步的请求
+	if allowed < 4 || allowed > 5 {
+		t.Fatalf("allowed = %d, want 4 or 5", allowed)
+	}
+}
+
+func TestSharedStoreFallsBackToLocal(t *testing.T) {
+	srv := newRESPServer(t)
+	addr := srv.url()
+	srv.ln.Close()
+
+	var errs int
+	store, _ := newTestSharedStore(t, addr, SharedOptions{RetryInterval: time.Minute, OnError: func(error) { errs++ }})
+	limit := Limit{Algorithm: SlidingWindow, Rate: 2, Period: time.Minute}
+
+	var allowed int
+	for i := 0; i < 3; i++ {
+		res, err := store.Allow(context.Background(), "k", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Fatalf("allowed = %d, want 2 from local fallback", allowed)
+	}
+	// 重试间隔内不再访问后端
+	if errs != 1 {
+		t.Fatalf("OnError called %d times, want 1", errs)
+	}
+}
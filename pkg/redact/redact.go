@@ -0,0 +1,269 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask 替换敏感数据的占位符
+const Mask = "[REDACTED]"
+
+// preset 预置的脱敏规则，在Patterns中以@开头引用
+type preset struct {
+	expr  string
+	valid func(match string) bool // 对匹配结果进一步校验，减少误判
+}
+
+var presets = map[string]preset{
+	// 13到19位的银行卡号，允许空格或短横线分隔，并通过Luhn校验
+	"@card":  {expr: `\b(?:\d[ -]?){12,18}\d\b`, valid: luhn},
+	"@email": {expr: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`},
+	// 中国大陆身份证号
+	"@idcard": {expr: `\b\d{17}[\dXx]\b`},
+}
+
+// pattern 编译后的正则规则
+type pattern struct {
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// Rules 脱敏规则
+//
+// JSONPaths使用.分隔的路径，如user.password，$.开头可省略；
+// *匹配任意一个键或数组下标，**匹配任意层级，数组会自动展开，如items.token匹配每个元素的token
+type Rules struct {
+	Headers    []string `json:"headers"`    // 请求头和响应头名称，不区分大小写
+	JSONPaths  []string `json:"jsonPaths"`  // JSON请求体和响应体中的字段路径
+	FormFields []string `json:"formFields"` // 查询参数和表单字段名称，不区分大小写
+	Patterns   []string `json:"patterns"`   // 正则表达式，匹配的内容会被替换，支持@card、@email、@idcard
+}
+
+// Defaults 始终生效的默认规则，用于屏蔽各类凭证
+var Defaults = Rules{
+	Headers: []string{
+		"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+		"X-Api-Key", "X-Amz-Security-Token", "X-Client-Cert-Fingerprint",
+	},
+	JSONPaths:  []string{"**.password", "**.secret", "**.access_token", "**.refresh_token"},
+	FormFields: []string{"password", "secret", "access_token", "refresh_token", "api_key", "X-Amz-Signature", "X-Amz-Security-Token"},
+}
+
+// Redactor 编译后的脱敏规则
+type Redactor struct {
+	headers  map[string]bool
+	paths    [][]string
+	fields   map[string]bool
+	patterns []pattern
+}
+
+// Compile合并并编译多组脱敏规则
+func Compile(rules ...Rules) (*Redactor, error) {
+	r := &Redactor{
+		headers: make(map[string]bool),
+		fields:  make(map[string]bool),
+	}
+	for _, rule := range rules {
+		for _, h := range rule.Headers {
+			r.headers[http.CanonicalHeaderKey(h)] = true
+		}
+		for _, p := range rule.JSONPaths {
+			p = strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+			if p == "" {
+				return nil, fmt.Errorf("invalid json path %q", p)
+			}
+			r.paths = append(r.paths, strings.Split(p, "."))
+		}
+		for _, f := range rule.FormFields {
+			r.fields[strings.ToLower(f)] = true
+		}
+		for _, p := range rule.Patterns {
+			pre := preset{expr: p}
+			if strings.HasPrefix(p, "@") {
+				var ok bool
+				if pre, ok = presets[p]; !ok {
+					return nil, fmt.Errorf("unknown pattern preset %q", p)
+				}
+			}
+			re, err := regexp.Compile(pre.expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+			}
+			r.patterns = append(r.patterns, pattern{re: re, valid: pre.valid})
+		}
+	}
+	return r, nil
+}
+
+// Header返回脱敏后的请求头副本，不修改原请求头
+func (r *Redactor) Header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		masked := make([]string, len(values))
+		for i, v := range values {
+			if r.headers[http.CanonicalHeaderKey(name)] {
+				masked[i] = Mask
+			} else {
+				masked[i] = r.text(v)
+			}
+		}
+		out[name] = masked
+	}
+	return out
+}
+
+// URL对地址中的查询参数脱敏
+func (r *Redactor) URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return r.text(raw)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = r.form(u.RawQuery)
+	}
+	return r.text(u.String())
+}
+
+// Body根据内容类型对请求体或响应体脱敏，配置了JSON字段规则而JSON无法解析时返回占位符
+func (r *Redactor) Body(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.Contains(mediaType, "json") && len(r.paths) > 0:
+		masked, ok := r.json(body)
+		if !ok {
+			// 无法解析（如格式错误或已截断）时不能确定字段的位置，整体替换，避免保存未脱敏的字段
+			return []byte(Mask)
+		}
+		body = masked
+	case mediaType == "application/x-www-form-urlencoded":
+		body = []byte(r.form(string(body)))
+	}
+	return []byte(r.text(string(body)))
+}
+
+// text使用正则表达式脱敏
+func (r *Redactor) text(s string) string {
+	for _, p := range r.patterns {
+		if p.valid == nil {
+			s = p.re.ReplaceAllString(s, Mask)
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.valid(match) {
+				return Mask
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// luhn使用Luhn算法校验卡号
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// form对URL编码的参数脱敏，未匹配的参数保持原样
+func (r *Redactor) form(raw string) string {
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		name, _, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		decoded, err := url.QueryUnescape(name)
+		if err != nil {
+			decoded = name
+		}
+		if r.fields[strings.ToLower(decoded)] {
+			pairs[i] = name + "=" + url.QueryEscape(Mask)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// json对JSON中匹配路径的字段脱敏，解析失败时返回false
+func (r *Redactor) json(body []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false
+	}
+	for _, path := range r.paths {
+		doc = maskPath(doc, path)
+	}
+	masked, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false
+	}
+	return masked, true
+}
+
+// maskPath将路径匹配的值替换为占位符
+func maskPath(v any, path []string) any {
+	if len(path) == 0 {
+		return Mask
+	}
+	if arr, ok := v.([]any); ok {
+		// 数组自动展开，*也可以显式匹配数组下标
+		sub := path
+		if path[0] == "*" {
+			sub = path[1:]
+		}
+		for i := range arr {
+			arr[i] = maskPath(arr[i], sub)
+		}
+		return arr
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+
+	seg, rest := path[0], path[1:]
+	switch seg {
+	case "**":
+		// **既可以匹配0层，也可以匹配多层
+		v = maskPath(obj, rest)
+		if obj, ok = v.(map[string]any); !ok {
+			return v
+		}
+		for k, child := range obj {
+			obj[k] = maskPath(child, path)
+		}
+	case "*":
+		for k, child := range obj {
+			obj[k] = maskPath(child, rest)
+		}
+	default:
+		if child, ok := obj[seg]; ok {
+			obj[seg] = maskPath(child, rest)
+		}
+	}
+	return obj
+}
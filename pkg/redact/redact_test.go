@@ -0,0 +1,32 @@
+package redact
+
+import "testing"
+
+func TestBody(t *testing.T) {
+	r, err := Compile(Defaults, Rules{JSONPaths: []string{"user.phone"}, Patterns: []string{"@email"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json field", "application/json", `{"user":{"name":"a","phone":"123"}}`, `{"user":{"name":"a","phone":"[REDACTED]"}}`},
+		{"json default", "application/vnd.api+json; charset=utf-8", `[{"password":"p"}]`, `[{"password":"[REDACTED]"}]`},
+		{"json pattern", "application/json", `{"mail":"a@example.com"}`, `{"mail":"[REDACTED]"}`},
+		{"invalid json", "application/json", `{"password":"p",}`, Mask},
+		{"truncated json", "application/json", `{"user":{"phone":"123","name":"a`, Mask},
+		{"form", "application/x-www-form-urlencoded", "user=a&password=p", "user=a&password=%5BREDACTED%5D"},
+		{"text pattern", "text/plain", "contact a@example.com", "contact [REDACTED]"},
+		{"empty", "application/json", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.Body(tt.contentType, []byte(tt.body))); got != tt.want {
+				t.Fatalf("Body() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
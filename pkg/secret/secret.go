@@ -0,0 +1,404 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// 主密钥环境变量，多个密钥用逗号分隔，第一个为当前密钥，其余仅用于解密
+	EnvMasterKey = "GATEWAY_MASTER_KEY"
+	// 密文前缀，用于区分明文和密文
+	cipherPrefix   = "enc:v1:"
+	envelopePrefix = "enc:v2:"
+)
+
+// 二进制信封的魔数，明文JSON不会以0字节开头
+var envelopeMagic = []byte("\x00GWE")
+
+const (
+	envelopeVersion = 2
+	dataKeySize     = 32
+)
+
+var (
+	mu       sync.RWMutex
+	keys     = make(map[string][]byte)
+	activeID string
+)
+
+// LoadKeys加载主密钥，优先读取环境变量，其次读取密钥文件，密钥文件不存在时自动生成
+// 密钥文件每行一个base64编码的32字节密钥，第一行为当前密钥，#开头的行为注释
+func LoadKeys(path string) ([][]byte, error) {
+	if v := os.Getenv(EnvMasterKey); v != "" {
+		return decodeKeys(strings.Split(v, ","))
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return decodeKeys(strings.Split(string(data), "\n"))
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := AddKeyToFile(path, key); err != nil {
+		return nil, err
+	}
+	return [][]byte{key}, nil
+}
+
+// GenerateKey生成新的主密钥
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// AddKeyToFile将密钥作为当前密钥写入密钥文件第一行，原有密钥保留用于解密
+func AddKeyToFile(path string, key []byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := base64.StdEncoding.EncodeToString(key) + "\n" + string(data)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SetKeys设置用于加解密的主密钥，第一个为加密使用的当前密钥
+func SetKeys(list [][]byte) error {
+	if len(list) == 0 {
+		return errors.New("no master key")
+	}
+	ring := make(map[string][]byte, len(list))
+	for _, key := range list {
+		if len(key) != 32 {
+			return errors.New("master key must be 32 bytes")
+		}
+		ring[KeyID(key)] = key
+	}
+	mu.Lock()
+	keys = ring
+	activeID = KeyID(list[0])
+	mu.Unlock()
+	return nil
+}
+
+// KeyID返回密钥ID，由密钥的SHA-256摘要派生，不会泄露密钥
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ActiveKeyID返回当前密钥ID
+func ActiveKeyID() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return activeID
+}
+
+// Encrypt加密字符串，空字符串不加密
+func Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	sealed, err := Seal([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt解密Encrypt生成的密文，没有密文前缀的值按明文原样返回
+func Decrypt(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envelopePrefix):
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, envelopePrefix))
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := Open(sealed)
+		return string(plaintext), err
+	case strings.HasPrefix(value, cipherPrefix):
+		return decryptV1(strings.TrimPrefix(value, cipherPrefix))
+	}
+	return value, nil
+}
+
+// Reencrypt使用当前密钥重新加密字符串，已使用当前密钥加密时返回false
+func Reencrypt(value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+	if strings.HasPrefix(value, envelopePrefix) {
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, envelopePrefix))
+		if err != nil {
+			return "", false, err
+		}
+		resealed, changed, err := Reseal(sealed)
+		if err != nil || !changed {
+			return value, false, err
+		}
+		return envelopePrefix + base64.StdEncoding.EncodeToString(resealed), true, nil
+	}
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return "", false, err
+	}
+	encrypted, err := Encrypt(plaintext)
+	return encrypted, err == nil, err
+}
+
+// Seal使用信封加密：每条记录生成随机数据密钥加密数据，数据密钥再由主密钥加密
+//
+// 格式：魔数(4) | 版本(1) | 密钥ID长度(1) | 密钥ID | 加密的数据密钥 | nonce | 密文
+func Seal(plaintext []byte) ([]byte, error) {
+	id, kek, err := activeKey()
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(kek, dataKey, []byte(id))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dataKey, plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return appendEnvelope(id, wrapped, ciphertext), nil
+}
+
+// Open解密Seal生成的数据，不是信封格式的数据按明文原样返回
+func Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := keyByID(env.keyID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := open(kek, env.wrapped, []byte(env.keyID))
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, env.ciphertext, nil)
+}
+
+// Reseal使用当前密钥重新加密数据，只需重新加密数据密钥，已使用当前密钥时返回false
+func Reseal(data []byte) ([]byte, bool, error) {
+	if !IsSealed(data) {
+		sealed, err := Seal(data)
+		return sealed, err == nil, err
+	}
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return nil, false, err
+	}
+	id, activeKEK, err := activeKey()
+	if err != nil {
+		return nil, false, err
+	}
+	if env.keyID == id {
+		return data, false, nil
+	}
+	kek, err := keyByID(env.keyID)
+	if err != nil {
+		return nil, false, err
+	}
+	dataKey, err := open(kek, env.wrapped, []byte(env.keyID))
+	if err != nil {
+		return nil, false, err
+	}
+	wrapped, err := seal(activeKEK, dataKey, []byte(id))
+	if err != nil {
+		return nil, false, err
+	}
+	return appendEnvelope(id, wrapped, env.ciphertext), true, nil
+}
+
+// IsSealed判断数据是否为信封格式
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, envelopeMagic)
+}
+
+// SealedKeyID返回加密数据使用的密钥ID
+func SealedKeyID(data []byte) (string, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return "", err
+	}
+	return env.keyID, nil
+}
+
+type envelope struct {
+	keyID      string
+	wrapped    []byte
+	ciphertext []byte
+}
+
+func appendEnvelope(id string, wrapped, ciphertext []byte) []byte {
+	out := make([]byte, 0, len(envelopeMagic)+2+len(id)+len(wrapped)+len(ciphertext))
+	out = append(out, envelopeMagic...)
+	out = append(out, envelopeVersion, byte(len(id)))
+	out = append(out, id...)
+	out = append(out, wrapped...)
+	return append(out, ciphertext...)
+}
+
+func parseEnvelope(data []byte) (*envelope, error) {
+	if !IsSealed(data) || len(data) < len(envelopeMagic)+2 {
+		return nil, errors.New("invalid envelope")
+	}
+	data = data[len(envelopeMagic):]
+	if data[0] != envelopeVersion {
+		return nil, fmt.Errorf("unsupported envelope version %d", data[0])
+	}
+	idLen := int(data[1])
+	data = data[2:]
+	// 加密的数据密钥长度：nonce + 数据密钥 + GCM标签
+	wrappedLen := 12 + dataKeySize + 16
+	if len(data) < idLen+wrappedLen {
+		return nil, errors.New("envelope too short")
+	}
+	return &envelope{
+		keyID:      string(data[:idLen]),
+		wrapped:    data[idLen : idLen+wrappedLen],
+		ciphertext: data[idLen+wrappedLen:],
+	}, nil
+}
+
+// seal使用AES-GCM加密，结果为nonce | 密文
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+// decryptV1解密旧格式的密文，旧格式没有密钥ID，依次尝试所有密钥
+func decryptV1(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(keys) == 0 {
+		return "", errors.New("master key not initialized")
+	}
+	ordered := [][]byte{keys[activeID]}
+	for id, key := range keys {
+		if id != activeID {
+			ordered = append(ordered, key)
+		}
+	}
+	for _, key := range ordered {
+		if plaintext, err := open(key, sealed, nil); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", errors.New("no master key can decrypt the value")
+}
+
+func activeKey() (string, []byte, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if activeID == "" {
+		return "", nil, errors.New("master key not initialized")
+	}
+	return activeID, keys[activeID], nil
+}
+
+func keyByID(id string) ([]byte, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	key, ok := keys[id]
+	if !ok {
+		return nil, fmt.Errorf("master key %s not found", id)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decodeKeys(lines []string) ([][]byte, error) {
+	var list [][]byte
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := decodeKey(line)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, key)
+	}
+	if len(list) == 0 {
+		return nil, errors.New("no master key found")
+	}
+	return list, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %v", err)
+	}
+	if len(key) != 32 {
+		return nil, errors.New("master key must be 32 bytes")
+	}
+	return key, nil
+}
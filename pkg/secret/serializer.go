@@ -0,0 +1,45 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("secret", Serializer{})
+}
+
+// Serializer 加密字符串字段的gorm序列化器，使用方式：`gorm:"serializer:secret"`
+type Serializer struct{}
+
+// Scan从数据库读取时解密
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		return fmt.Errorf("unsupported secret value type %T", dbValue)
+	}
+
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return err
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value写入数据库时加密
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported secret field type %T", fieldValue)
+	}
+	return Encrypt(plaintext)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"gorm.io/gorm"
 )
@@ -50,7 +51,7 @@ func (bs *BaseService[T]) GetById(ctx context.Context, model T) error {
 
 // GetByCondition根据给定条件获取单个记录
 func (bs *BaseService[T]) GetByCondition(ctx context.Context, condition Condition) (T, error) {
-	var model T
+	model := newModel[T]()
 	err := condition(bs.DB.WithContext(ctx)).First(model).Error
 	return model, err
 }
@@ -89,6 +90,12 @@ func (bs *BaseService[T]) DeleteById(ctx context.Context, model T) error {
 
 // DeleteByCondition根据条件删除记录
 func (bs *BaseService[T]) DeleteByCondition(ctx context.Context, condition Condition) error {
-	var model T
+	model := newModel[T]()
 	return condition(bs.DB.WithContext(ctx)).Delete(model).Error
 }
+
+// newModel为指针类型的模型分配一个新的实例
+func newModel[T DataModelInterface]() T {
+	var model T
+	return reflect.New(reflect.TypeOf(model).Elem()).Interface().(T)
+}
@@ -0,0 +1,164 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HMAC签名算法标识
+	AlgorithmHMACSHA256 = "HMAC-SHA256"
+	// 签名时间戳请求头（Unix秒）
+	HeaderTimestamp = "X-Gateway-Timestamp"
+	// 签名随机数请求头，用于防重放
+	HeaderNonce = "X-Gateway-Nonce"
+	// 请求体摘要请求头
+	HeaderContentSHA256 = "X-Gateway-Content-Sha256"
+)
+
+// Authorization 为HMAC签名的Authorization请求头内容
+// 格式：HMAC-SHA256 Credential=<key>, SignedHeaders=host;content-type, Signature=<hex>
+type Authorization struct {
+	Credential    string
+	SignedHeaders []string
+	Signature     string
+}
+
+// String将签名信息格式化为Authorization请求头的值
+func (a Authorization) String() string {
+	return fmt.Sprintf("%s Credential=%s, SignedHeaders=%s, Signature=%s",
+		AlgorithmHMACSHA256, a.Credential, strings.Join(a.SignedHeaders, ";"), a.Signature)
+}
+
+// ParseAuthorization解析HMAC签名的Authorization请求头
+func ParseAuthorization(value string) (*Authorization, error) {
+	scheme, params, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || scheme != AlgorithmHMACSHA256 {
+		return nil, errors.New("unsupported authorization scheme")
+	}
+
+	auth := &Authorization{}
+	for _, part := range strings.Split(params, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed authorization parameter %q", part)
+		}
+		switch k {
+		case "Credential":
+			auth.Credential = v
+		case "SignedHeaders":
+			if v != "" {
+				auth.SignedHeaders = strings.Split(strings.ToLower(v), ";")
+			}
+		case "Signature":
+			auth.Signature = v
+		}
+	}
+	if auth.Credential == "" || auth.Signature == "" {
+		return nil, errors.New("authorization missing credential or signature")
+	}
+	return auth, nil
+}
+
+// HashBody计算请求体的SHA256摘要（十六进制）
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalRequest构造参与签名的规范化字符串，
+// 依次包含请求方法、路径、排序后的查询参数、时间戳、随机数、签名请求头和请求体摘要
+func CanonicalRequest(r *http.Request, signedHeaders []string, timestamp, nonce, bodyHash string) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+	b.WriteByte('\n')
+	b.WriteString(r.URL.Query().Encode())
+	b.WriteByte('\n')
+	b.WriteString(timestamp)
+	b.WriteByte('\n')
+	b.WriteString(nonce)
+	b.WriteByte('\n')
+	for _, name := range signedHeaders {
+		name = strings.ToLower(name)
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(headerValue(r, name))
+		b.WriteByte('\n')
+	}
+	b.WriteString(bodyHash)
+	return b.String()
+}
+
+// Sum计算HMAC-SHA256签名（十六进制）
+func Sum(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Equal以常量时间比较两个十六进制签名
+func Equal(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// SignHMAC对请求进行HMAC签名，设置时间戳、随机数、请求体摘要和Authorization请求头
+func SignHMAC(r *http.Request, body []byte, keyID, secret string, signedHeaders []string) error {
+	nonce, err := NewNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	bodyHash := HashBody(body)
+	r.Header.Set(HeaderTimestamp, timestamp)
+	r.Header.Set(HeaderNonce, nonce)
+	r.Header.Set(HeaderContentSHA256, bodyHash)
+
+	headers := make([]string, 0, len(signedHeaders))
+	for _, h := range signedHeaders {
+		headers = append(headers, strings.ToLower(h))
+	}
+	sort.Strings(headers)
+
+	canonical := CanonicalRequest(r, headers, timestamp, nonce, bodyHash)
+	auth := Authorization{
+		Credential:    keyID,
+		SignedHeaders: headers,
+		Signature:     Sum(secret, canonical),
+	}
+	r.Header.Set("Authorization", auth.String())
+	return nil
+}
+
+// NewNonce生成一个随机数
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func headerValue(r *http.Request, name string) string {
+	if name == "host" {
+		if r.Host != "" {
+			return r.Host
+		}
+		return r.URL.Host
+	}
+	return strings.TrimSpace(strings.Join(r.Header.Values(name), ","))
+}
@@ -0,0 +1,229 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// 支持的SOAP版本
+const (
+	Version11 = "1.1"
+	Version12 = "1.2"
+)
+
+// 各版本信封的命名空间
+var envelopeNamespaces = map[string]string{
+	Version11: "http://schemas.xmlsoap.org/soap/envelope/",
+	Version12: "http://www.w3.org/2003/05/soap-envelope",
+}
+
+// Options SOAP桥接配置
+type Options struct {
+	Version  string // SOAP版本，1.1或1.2，为空时为1.1
+	Action   string // SOAPAction
+	Template string // 生成soap:Body内容的text/template模板
+}
+
+// Data 渲染请求模板的数据，所有字符串在渲染前已经转义为XML文本
+type Data struct {
+	Body   any               // 解码后的JSON请求体，没有请求体时为空对象
+	Query  map[string]string // 查询参数，多个值时取第一个
+	Path   map[string]string // 路由路径参数
+	Header map[string]string // 请求头，多个值时取第一个
+}
+
+// Bridge 编译后的SOAP桥接，把JSON请求转换为SOAP请求，把SOAP响应转换为JSON
+type Bridge struct {
+	version string
+	action  string
+	tmpl    *template.Template
+}
+
+// Compile校验并编译SOAP桥接配置
+func Compile(opts Options) (*Bridge, error) {
+	version := opts.Version
+	if version == "" {
+		version = Version11
+	}
+	if _, ok := envelopeNamespaces[version]; !ok {
+		return nil, fmt.Errorf("unsupported soap version %q, expected 1.1 or 1.2", opts.Version)
+	}
+	if strings.ContainsAny(opts.Action, "\"\r\n") {
+		return nil, fmt.Errorf("invalid soap action %q", opts.Action)
+	}
+	if strings.TrimSpace(opts.Template) == "" {
+		return nil, fmt.Errorf("template is required")
+	}
+	tmpl, err := template.New("soap").Parse(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	return &Bridge{version: version, action: opts.Action, tmpl: tmpl}, nil
+}
+
+// Request渲染模板并生成SOAP信封
+func (b *Bridge) Request(data Data) ([]byte, error) {
+	if data.Body == nil {
+		data.Body = map[string]any{}
+	}
+	data = Data{
+		Body:   escapeValue(data.Body),
+		Query:  escapeMap(data.Query),
+		Path:   escapeMap(data.Path),
+		Header: escapeMap(data.Header),
+	}
+	var content bytes.Buffer
+	if err := b.tmpl.Execute(&content, data); err != nil {
+		return nil, err
+	}
+	// 不存在的字段输出为<no value>，数据中的<和>都已转义，这里出现的只能是模板的输出
+	body := strings.ReplaceAll(content.String(), "<no value>", "")
+
+	var envelope bytes.Buffer
+	envelope.WriteString(xml.Header)
+	envelope.WriteString(`<soap:Envelope xmlns:soap="` + envelopeNamespaces[b.version] + `"><soap:Body>`)
+	envelope.WriteString(body)
+	envelope.WriteString(`</soap:Body></soap:Envelope>`)
+	if _, err := ParseXML(envelope.Bytes()); err != nil {
+		return nil, fmt.Errorf("template produced invalid XML: %v", err)
+	}
+	return envelope.Bytes(), nil
+}
+
+// SetHeaders设置SOAP请求的Content-Type、SOAPAction和Accept
+func (b *Bridge) SetHeaders(h http.Header) {
+	if b.version == Version12 {
+		contentType := "application/soap+xml; charset=utf-8"
+		if b.action != "" {
+			contentType += `; action="` + b.action + `"`
+		}
+		h.Set("Content-Type", contentType)
+		h.Set("Accept", "application/soap+xml, text/xml")
+		return
+	}
+	h.Set("Content-Type", "text/xml; charset=utf-8")
+	// 部分SOAP服务区分请求头的大小写，不使用规范化的Soapaction
+	h.Del("SOAPAction")
+	h["SOAPAction"] = []string{`"` + b.action + `"`}
+	h.Set("Accept", "text/xml")
+}
+
+// Fault SOAP错误
+type Fault struct {
+	Code   string
+	Reason string
+	Detail any
+}
+
+// Status返回SOAP错误对应的HTTP状态码，调用方的错误为400，其他为502
+func (f *Fault) Status() int {
+	code := f.Code
+	if i := strings.LastIndex(code, ":"); i >= 0 {
+		code = code[i+1:]
+	}
+	switch code {
+	case "Client", "Sender":
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+// Response把SOAP响应转换为JSON，返回状态码和响应体
+// 正常响应为soap:Body的内容，SOAP错误转换为对应的状态码和error、code、detail字段
+func (b *Bridge) Response(status int, body []byte) (int, []byte) {
+	root, err := ParseXML(body)
+	if err != nil || root.Name.Local != "Envelope" || root.Child("Body") == nil {
+		return http.StatusBadGateway, mustJSON(map[string]any{"error": "Invalid SOAP response"})
+	}
+	soapBody := root.Child("Body")
+	if fault := parseFault(soapBody.Child("Fault")); fault != nil {
+		return fault.Status(), mustJSON(map[string]any{"error": fault.Reason, "code": fault.Code, "detail": fault.Detail})
+	}
+	if status >= http.StatusMultipleChoices {
+		return http.StatusBadGateway, mustJSON(map[string]any{"error": "Invalid SOAP response"})
+	}
+	result, ok := soapBody.JSON().(map[string]any)
+	if !ok {
+		result = map[string]any{}
+	}
+	return status, mustJSON(result)
+}
+
+// parseFault解析SOAP 1.1或1.2的Fault元素
+func parseFault(node *Node) *Fault {
+	if node == nil {
+		return nil
+	}
+	fault := &Fault{}
+	if code := node.Child("faultcode"); code != nil {
+		// SOAP 1.1
+		fault.Code = strings.TrimSpace(code.Text)
+		if reason := node.Child("faultstring"); reason != nil {
+			fault.Reason = strings.TrimSpace(reason.Text)
+		}
+		if detail := node.Child("detail"); detail != nil {
+			fault.Detail = detail.JSON()
+		}
+		return fault
+	}
+	// SOAP 1.2
+	if code := node.Child("Code"); code != nil {
+		if value := code.Child("Value"); value != nil {
+			fault.Code = strings.TrimSpace(value.Text)
+		}
+	}
+	if reason := node.Child("Reason"); reason != nil {
+		if text := reason.Child("Text"); text != nil {
+			fault.Reason = strings.TrimSpace(text.Text)
+		}
+	}
+	if detail := node.Child("Detail"); detail != nil {
+		fault.Detail = detail.JSON()
+	}
+	return fault
+}
+
+// escapeValue把JSON值中的字符串转义为XML文本
+func escapeValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return escapeText(v)
+	case map[string]any:
+		escaped := make(map[string]any, len(v))
+		for key, value := range v {
+			escaped[key] = escapeValue(value)
+		}
+		return escaped
+	case []any:
+		escaped := make([]any, len(v))
+		for i, value := range v {
+			escaped[i] = escapeValue(value)
+		}
+		return escaped
+	}
+	return v
+}
+
+func escapeMap(m map[string]string) map[string]string {
+	escaped := make(map[string]string, len(m))
+	for key, value := range m {
+		escaped[key] = escapeText(value)
+	}
+	return escaped
+}
+
+func escapeText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,113 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Node XML元素
+type Node struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Children []*Node
+	Text     string
+}
+
+// ParseXML解析XML文档，返回根元素
+func ParseXML(data []byte) (*Node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		root  *Node
+		stack []*Node
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &Node{Name: t.Name, Attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else if root != nil {
+				return nil, fmt.Errorf("multiple root elements")
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty XML document")
+	}
+	return root, nil
+}
+
+// Child返回指定本地名称的第一个子元素，忽略命名空间
+func (n *Node) Child(local string) *Node {
+	for _, child := range n.Children {
+		if child.Name.Local == local {
+			return child
+		}
+	}
+	return nil
+}
+
+// JSON把元素转换为JSON值，忽略命名空间前缀
+//
+// 只有文本的元素转换为字符串，xsi:nil="true"的元素转换为null；
+// 其他元素转换为对象，属性的键以@开头，文本的键为#text，同名的子元素转换为数组
+func (n *Node) JSON() any {
+	attrs := make(map[string]any)
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		if attr.Name.Local == "nil" && attr.Value == "true" {
+			return nil
+		}
+		attrs["@"+attr.Name.Local] = attr.Value
+	}
+	text := strings.TrimSpace(n.Text)
+	if len(n.Children) == 0 && len(attrs) == 0 {
+		return text
+	}
+
+	obj := attrs
+	for _, child := range n.Children {
+		value := child.JSON()
+		existing, ok := obj[child.Name.Local]
+		switch {
+		case !ok:
+			obj[child.Name.Local] = value
+		case isList(existing):
+			obj[child.Name.Local] = append(existing.([]any), value)
+		default:
+			obj[child.Name.Local] = []any{existing, value}
+		}
+	}
+	if text != "" {
+		obj["#text"] = text
+	}
+	return obj
+}
+
+// isList判断值是否为同名子元素组成的数组
+func isList(v any) bool {
+	_, ok := v.([]any)
+	return ok
+}
@@ -0,0 +1,144 @@
+package threat
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSONLimits JSON结构限制，值为0表示不限制
+type JSONLimits struct {
+	MaxDepth        int // 最大嵌套深度
+	MaxArrayLength  int // 数组最大元素数
+	MaxStringLength int // 字符串（包括键名）最大长度
+	MaxObjectKeys   int // 对象最大键数
+}
+
+// XMLLimits XML结构限制
+type XMLLimits struct {
+	MaxDepth int  // 最大嵌套深度，0表示不限制
+	AllowDTD bool // 是否允许DOCTYPE声明，禁止时可以防止实体扩展和外部实体攻击
+}
+
+// Violation 请求违反了限制
+type Violation struct {
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return v.Reason
+}
+
+func violation(format string, args ...any) error {
+	return &Violation{Reason: fmt.Sprintf(format, args...)}
+}
+
+// IsViolation判断错误是否为违反限制
+func IsViolation(err error) bool {
+	var v *Violation
+	return errors.As(err, &v)
+}
+
+// jsonFrame 当前所在的JSON对象或数组
+type jsonFrame struct {
+	object bool
+	count  int
+}
+
+// CheckJSON以流式方式检查JSON结构，不会把整个文档解析到内存中
+func CheckJSON(data []byte, limits JSONLimits) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []jsonFrame
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return violation("malformed json: %v", err)
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{', '[':
+				if err := countElement(stack, limits); err != nil {
+					return err
+				}
+				stack = append(stack, jsonFrame{object: v == '{'})
+				if limits.MaxDepth > 0 && len(stack) > limits.MaxDepth {
+					return violation("json depth exceeds %d", limits.MaxDepth)
+				}
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if limits.MaxStringLength > 0 && len(v) > limits.MaxStringLength {
+				return violation("json string length exceeds %d", limits.MaxStringLength)
+			}
+			if err := countElement(stack, limits); err != nil {
+				return err
+			}
+		default:
+			if err := countElement(stack, limits); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// countElement对当前容器的元素计数，对象中键和值都会计数，因此键数为计数的一半
+func countElement(stack []jsonFrame, limits JSONLimits) error {
+	if len(stack) == 0 {
+		return nil
+	}
+	top := &stack[len(stack)-1]
+	top.count++
+	if top.object {
+		keys := (top.count + 1) / 2
+		if limits.MaxObjectKeys > 0 && keys > limits.MaxObjectKeys {
+			return violation("json object keys exceed %d", limits.MaxObjectKeys)
+		}
+		return nil
+	}
+	if limits.MaxArrayLength > 0 && top.count > limits.MaxArrayLength {
+		return violation("json array length exceeds %d", limits.MaxArrayLength)
+	}
+	return nil
+}
+
+// CheckXML以流式方式检查XML结构
+func CheckXML(data []byte, limits XMLLimits) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return violation("malformed xml: %v", err)
+		}
+		switch v := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+				return violation("xml depth exceeds %d", limits.MaxDepth)
+			}
+		case xml.EndElement:
+			depth--
+		case xml.Directive:
+			// DOCTYPE中可以声明实体，禁止后可防止实体扩展（billion laughs）和外部实体攻击
+			directive := strings.ToUpper(strings.TrimSpace(string(v)))
+			if strings.HasPrefix(directive, "DOCTYPE") && !limits.AllowDTD {
+				return violation("xml doctype declarations are not allowed")
+			}
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSON请求体和响应体的转换操作，除请求头规则中的rename、set和remove外还支持以下操作
+const (
+	ActionMove    = "move"    // 把字段移动到To指定的路径，用于移入或移出嵌套的对象
+	ActionDefault = "default" // 字段不存在或为null时设置为Value
+	ActionCoerce  = "coerce"  // 把字段转换为Type指定的类型，无法转换时保持不变
+	ActionWrap    = "wrap"    // 把整个JSON放到To指定的字段中，如{"data": ...}
+	ActionUnwrap  = "unwrap"  // 使用Path指定的字段替换整个JSON
+)
+
+// BodyRule JSON请求体或响应体的转换规则
+//
+// Path和To使用.分隔的字段路径，如user.name，路径段为*时对数组的每个元素执行，如items.*.price
+type BodyRule struct {
+	Action string
+	Path   string
+	To     string          // rename的新字段名称，move的目标路径，wrap的外层字段名称
+	Value  json.RawMessage // set和default的值，为JSON
+	Type   string          // coerce的目标类型，string、number、integer或boolean
+}
+
+// BodyRules 编译后的JSON转换规则，按顺序执行
+type BodyRules []compiledBodyRule
+
+type compiledBodyRule struct {
+	action string
+	path   []string
+	to     []string
+	value  json.RawMessage
+	typ    string
+}
+
+// CompileBodyRules校验并编译JSON转换规则
+func CompileBodyRules(rules []BodyRule) (BodyRules, error) {
+	compiled := make(BodyRules, 0, len(rules))
+	for i, rule := range rules {
+		c := compiledBodyRule{action: strings.ToLower(rule.Action)}
+		var err error
+		switch c.action {
+		case ActionRename:
+			c.path, err = parseFieldPath(rule.Path, true)
+			if err == nil && (rule.To == "" || strings.Contains(rule.To, ".")) {
+				err = fmt.Errorf("invalid field name %q", rule.To)
+			}
+			c.to = []string{rule.To}
+		case ActionMove:
+			if c.path, err = parseFieldPath(rule.Path, false); err == nil {
+				c.to, err = parseFieldPath(rule.To, false)
+			}
+		case ActionRemove:
+			c.path, err = parseFieldPath(rule.Path, true)
+		case ActionSet, ActionDefault:
+			if c.path, err = parseFieldPath(rule.Path, true); err == nil {
+				_, err = decodeJSON(rule.Value)
+				c.value = rule.Value
+			}
+		case ActionCoerce:
+			c.path, err = parseFieldPath(rule.Path, true)
+			switch rule.Type {
+			case "string", "number", "integer", "boolean":
+				c.typ = rule.Type
+			default:
+				err = fmt.Errorf("unknown type %q, expected string, number, integer or boolean", rule.Type)
+			}
+		case ActionWrap:
+			if rule.To == "" {
+				err = fmt.Errorf("wrap requires the envelope field in To")
+			}
+			c.to = []string{rule.To}
+		case ActionUnwrap:
+			c.path, err = parseFieldPath(rule.Path, false)
+		default:
+			err = fmt.Errorf("unknown action %q", rule.Action)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// parseFieldPath解析字段路径，wildcard为false时不允许使用*
+func parseFieldPath(path string, wildcard bool) ([]string, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid field path %q", path)
+		}
+		if segment == "*" && (!wildcard || i == len(segments)-1) {
+			return nil, fmt.Errorf("* is not allowed here in field path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// decodeJSON解码JSON，数字保持原样以免丢失精度
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// Apply按顺序对JSON执行转换，返回转换后的JSON
+func (rules BodyRules) Apply(data []byte) ([]byte, error) {
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		doc = rule.apply(doc)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (rule compiledBodyRule) apply(doc any) any {
+	switch rule.action {
+	case ActionWrap:
+		return map[string]any{rule.to[0]: doc}
+	case ActionUnwrap:
+		if value, ok := lookupField(doc, rule.path); ok {
+			return value
+		}
+		return doc
+	case ActionMove:
+		if value, ok := lookupField(doc, rule.path); ok {
+			eachParent(doc, rule.path, false, func(obj map[string]any, key string) { delete(obj, key) })
+			eachParent(doc, rule.to, true, func(obj map[string]any, key string) { obj[key] = value })
+		}
+		return doc
+	}
+	eachParent(doc, rule.path, rule.action == ActionSet || rule.action == ActionDefault, func(obj map[string]any, key string) {
+		switch rule.action {
+		case ActionRename:
+			if value, ok := obj[key]; ok {
+				delete(obj, key)
+				obj[rule.to[0]] = value
+			}
+		case ActionRemove:
+			delete(obj, key)
+		case ActionSet:
+			obj[key] = rule.newValue()
+		case ActionDefault:
+			if value, ok := obj[key]; !ok || value == nil {
+				obj[key] = rule.newValue()
+			}
+		case ActionCoerce:
+			if value, ok := obj[key]; ok {
+				obj[key] = coerce(value, rule.typ)
+			}
+		}
+	})
+	return doc
+}
+
+// newValue返回set和default的值，每次解码新的副本，后续规则修改该值时不影响其他位置和请求
+func (rule compiledBodyRule) newValue() any {
+	value, _ := decodeJSON(rule.value)
+	return value
+}
+
+// eachParent对路径最后一段所在的每个对象调用fn，create为true时创建不存在的中间对象
+func eachParent(node any, path []string, create bool, fn func(obj map[string]any, key string)) {
+	obj, ok := node.(map[string]any)
+	if len(path) == 1 {
+		if ok {
+			fn(obj, path[0])
+		}
+		return
+	}
+	if path[0] == "*" {
+		switch node := node.(type) {
+		case []any:
+			for _, item := range node {
+				eachParent(item, path[1:], create, fn)
+			}
+		case map[string]any:
+			for _, item := range node {
+				eachParent(item, path[1:], create, fn)
+			}
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+	child, exists := obj[path[0]]
+	if (!exists || child == nil) && create {
+		child = make(map[string]any)
+		obj[path[0]] = child
+	}
+	eachParent(child, path[1:], create, fn)
+}
+
+// lookupField返回路径对应的值，路径中不能有*
+func lookupField(node any, path []string) (any, bool) {
+	for _, key := range path {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// JSON数字的格式
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerce把值转换为指定类型，无法转换时返回原值
+func coerce(value any, typ string) any {
+	switch typ {
+	case "string":
+		switch v := value.(type) {
+		case json.Number:
+			return v.String()
+		case bool:
+			return strconv.FormatBool(v)
+		}
+	case "number", "integer":
+		var s string
+		switch v := value.(type) {
+		case json.Number:
+			s = v.String()
+		case string:
+			s = strings.TrimSpace(v)
+		case bool:
+			if v {
+				return json.Number("1")
+			}
+			return json.Number("0")
+		default:
+			return value
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return value
+		}
+		if typ == "integer" {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return json.Number(strconv.FormatInt(i, 10))
+			}
+			if f < math.MinInt64 || f > math.MaxInt64 {
+				return value
+			}
+			return json.Number(strconv.FormatInt(int64(f), 10))
+		}
+		if !jsonNumber.MatchString(s) {
+			s = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return json.Number(s)
+	case "boolean":
+		switch v := value.(type) {
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b
+			}
+		case json.Number:
+			return v.String() != "0"
+		}
+	}
+	return value
+}
@@ -0,0 +1,102 @@
+package transform
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBodyRulesApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []BodyRule
+		in    string
+		want  string
+	}{
+		{"rename", []BodyRule{{Action: "rename", Path: "user.name", To: "fullName"}},
+			`{"user":{"name":"a","id":1}}`, `{"user":{"fullName":"a","id":1}}`},
+		{"rename missing", []BodyRule{{Action: "rename", Path: "user.name", To: "fullName"}},
+			`{"user":{"id":1}}`, `{"user":{"id":1}}`},
+		{"remove in array", []BodyRule{{Action: "remove", Path: "items.*.secret"}},
+			`{"items":[{"id":1,"secret":"x"},{"id":2,"secret":"y"}]}`, `{"items":[{"id":1},{"id":2}]}`},
+		{"set creates objects", []BodyRule{{Action: "set", Path: "meta.source", Value: json.RawMessage(`"gateway"`)}},
+			`{"id":1}`, `{"id":1,"meta":{"source":"gateway"}}`},
+		{"set object values are independent", []BodyRule{
+			{Action: "set", Path: "items.*.tags", Value: json.RawMessage(`[]`)},
+			{Action: "rename", Path: "items.*.id", To: "key"},
+		}, `{"items":[{"id":1},{"id":2}]}`, `{"items":[{"key":1,"tags":[]},{"key":2,"tags":[]}]}`},
+		{"default", []BodyRule{{Action: "default", Path: "page", Value: json.RawMessage(`1`)}, {Action: "default", Path: "size", Value: json.RawMessage(`20`)}},
+			`{"page":3,"size":null}`, `{"page":3,"size":20}`},
+		{"move into nested object", []BodyRule{{Action: "move", Path: "city", To: "address.city"}},
+			`{"city":"x","name":"a"}`, `{"address":{"city":"x"},"name":"a"}`},
+		{"move out of nested object", []BodyRule{{Action: "move", Path: "data.user", To: "user"}},
+			`{"data":{"user":{"id":1}}}`, `{"data":{},"user":{"id":1}}`},
+		{"wrap", []BodyRule{{Action: "wrap", To: "data"}},
+			`[1,2]`, `{"data":[1,2]}`},
+		{"unwrap", []BodyRule{{Action: "unwrap", Path: "data.items"}},
+			`{"data":{"items":[1]},"total":1}`, `[1]`},
+		{"unwrap missing", []BodyRule{{Action: "unwrap", Path: "data"}},
+			`{"items":[1]}`, `{"items":[1]}`},
+		{"coerce", []BodyRule{
+			{Action: "coerce", Path: "id", Type: "string"},
+			{Action: "coerce", Path: "price", Type: "number"},
+			{Action: "coerce", Path: "count", Type: "integer"},
+			{Action: "coerce", Path: "active", Type: "boolean"},
+			{Action: "coerce", Path: "bad", Type: "number"},
+		}, `{"id":12345678901234567890,"price":" 1.50 ","count":"7.9","active":"true","bad":"abc"}`,
+			`{"active":true,"bad":"abc","count":7,"id":"12345678901234567890","price":1.50}`},
+		{"large numbers keep precision", []BodyRule{{Action: "remove", Path: "x"}},
+			`{"id":9007199254740993,"x":1}`, `{"id":9007199254740993}`},
+		{"html is not escaped", []BodyRule{{Action: "set", Path: "link", Value: json.RawMessage(`"<a&b>"`)}},
+			`{}`, `{"link":"<a&b>"}`},
+		{"rules run in order", []BodyRule{
+			{Action: "wrap", To: "data"},
+			{Action: "set", Path: "data.ok", Value: json.RawMessage(`true`)},
+		}, `{"id":1}`, `{"data":{"id":1,"ok":true}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := CompileBodyRules(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := rules.Apply([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyRulesApplyInvalidJSON(t *testing.T) {
+	rules, err := CompileBodyRules([]BodyRule{{Action: "remove", Path: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range []string{``, `{`, `{"a":1} {"b":2}`, `<xml/>`} {
+		if _, err := rules.Apply([]byte(in)); err == nil {
+			t.Errorf("Apply(%q) should fail", in)
+		}
+	}
+}
+
+func TestCompileBodyRulesErrors(t *testing.T) {
+	for name, rule := range map[string]BodyRule{
+		"unknown action":       {Action: "copy", Path: "a"},
+		"empty path":           {Action: "remove"},
+		"empty segment":        {Action: "remove", Path: "a..b"},
+		"trailing wildcard":    {Action: "remove", Path: "items.*"},
+		"wildcard in move":     {Action: "move", Path: "items.*.id", To: "id"},
+		"rename to path":       {Action: "rename", Path: "a", To: "b.c"},
+		"invalid value":        {Action: "set", Path: "a", Value: json.RawMessage(`{`)},
+		"unknown type":         {Action: "coerce", Path: "a", Type: "date"},
+		"wrap without field":   {Action: "wrap"},
+		"unwrap with wildcard": {Action: "unwrap", Path: "*.a"},
+	} {
+		if _, err := CompileBodyRules([]BodyRule{rule}); err == nil {
+			t.Errorf("%s: CompileBodyRules(%+v) should fail", name, rule)
+		}
+	}
+}
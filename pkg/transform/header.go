@@ -0,0 +1,102 @@
+package transform
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 请求头和响应头的转换操作
+const (
+	ActionAdd    = "add"    // 追加一个值
+	ActionSet    = "set"    // 设置值，替换已有的值
+	ActionRemove = "remove" // 删除请求头
+	ActionRename = "rename" // 重命名，源请求头存在时替换目标请求头
+)
+
+// HeaderRule 请求头或响应头的转换规则
+type HeaderRule struct {
+	Action string
+	Name   string
+	Value  string // add和set的值，支持模板变量，如{client_ip}
+	To     string // rename的目标名称
+}
+
+// HeaderRules 编译后的转换规则，按顺序执行
+type HeaderRules []compiledHeaderRule
+
+type compiledHeaderRule struct {
+	action string
+	name   string
+	to     string
+	value  *Template
+}
+
+// CompileHeaderRules校验并编译转换规则
+func CompileHeaderRules(rules []HeaderRule) (HeaderRules, error) {
+	compiled := make(HeaderRules, 0, len(rules))
+	for i, rule := range rules {
+		if !validHeaderName(rule.Name) {
+			return nil, fmt.Errorf("rule %d: invalid header name %q", i, rule.Name)
+		}
+		c := compiledHeaderRule{
+			action: strings.ToLower(rule.Action),
+			name:   http.CanonicalHeaderKey(rule.Name),
+		}
+		switch c.action {
+		case ActionAdd, ActionSet:
+			value, err := ParseTemplate(rule.Value, RequestVariable)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+			c.value = value
+		case ActionRemove:
+		case ActionRename:
+			if !validHeaderName(rule.To) {
+				return nil, fmt.Errorf("rule %d: invalid target header name %q", i, rule.To)
+			}
+			c.to = http.CanonicalHeaderKey(rule.To)
+		default:
+			return nil, fmt.Errorf("rule %d: unknown action %q, expected add, set, remove or rename", i, rule.Action)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// Apply按顺序对头部执行转换
+func (rules HeaderRules) Apply(h http.Header, vars Vars) {
+	for _, rule := range rules {
+		switch rule.action {
+		case ActionAdd:
+			h.Add(rule.name, headerValue(rule.value.Execute(vars)))
+		case ActionSet:
+			h.Set(rule.name, headerValue(rule.value.Execute(vars)))
+		case ActionRemove:
+			h.Del(rule.name)
+		case ActionRename:
+			if values, ok := h[rule.name]; ok {
+				h.Del(rule.name)
+				h[rule.to] = values
+			}
+		}
+	}
+}
+
+// headerValue去掉模板展开后的换行，防止通过变量注入请求头
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
+
+// validHeaderName判断是否为合法的头部名称
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
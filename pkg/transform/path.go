@@ -0,0 +1,178 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PathPattern 路由的路径模式
+//
+// 普通路径按路径段前缀匹配，如/users匹配/users和/users/1
+// {name}匹配一个路径段，{name:正则}匹配正则表达式，*匹配任意字符（包括/），一个模式只能有一个*
+// 以~开头时其余部分为正则表达式，命名分组作为路径参数，如~^/v(?P<version>\d+)/
+// 没有*的模式匹配的是路径前缀，剩余的路径保存在参数*中
+type PathPattern struct {
+	pattern  string
+	re       *regexp.Regexp
+	params   map[string]string // 正则分组名与参数名的对应关系
+	regex    bool              // 是否为以~开头的正则表达式模式
+	wildcard bool              // 模式中是否有*，有*时必须匹配完整路径
+	literal  int               // 模式中字面字符的数量，用于多个路由匹配时选择更具体的路由
+}
+
+// 参数名称只能包含字母、数字和下划线
+var paramName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CompilePath编译路径模式
+func CompilePath(pattern string) (*PathPattern, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty path pattern")
+	}
+	if strings.HasPrefix(pattern, "~") {
+		return compileRegexPath(pattern)
+	}
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("path pattern %q must start with /", pattern)
+	}
+
+	p := &PathPattern{pattern: pattern, params: make(map[string]string)}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			end := closingBrace(pattern, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed parameter in path pattern %q", pattern)
+			}
+			name, expr, ok := strings.Cut(pattern[i+1:end], ":")
+			if !paramName.MatchString(name) {
+				return nil, fmt.Errorf("invalid parameter name %q in path pattern %q", name, pattern)
+			}
+			if !ok {
+				expr = "[^/]+"
+			} else if _, err := regexp.Compile(expr); err != nil {
+				return nil, fmt.Errorf("invalid regexp for parameter %s: %v", name, err)
+			}
+			for _, existing := range p.params {
+				if existing == name {
+					return nil, fmt.Errorf("duplicate parameter %s in path pattern %q", name, pattern)
+				}
+			}
+			group := "p" + strconv.Itoa(len(p.params))
+			p.params[group] = name
+			b.WriteString("(?P<" + group + ">" + expr + ")")
+			i = end
+		case '}':
+			return nil, fmt.Errorf("unexpected } in path pattern %q", pattern)
+		case '*':
+			if p.wildcard {
+				return nil, fmt.Errorf("path pattern %q has more than one *", pattern)
+			}
+			p.wildcard = true
+			b.WriteString("(?P<rest>.*)")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			p.literal++
+		}
+	}
+	switch {
+	case p.wildcard:
+	case strings.HasSuffix(pattern, "/"):
+		b.WriteString("(?P<rest>.*)")
+	default:
+		b.WriteString("(?:/(?P<rest>.*))?")
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid path pattern %q: %v", pattern, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// compileRegexPath编译以~开头的正则表达式模式，正则表达式匹配的是路径前缀
+func compileRegexPath(pattern string) (*PathPattern, error) {
+	re, err := regexp.Compile("^(?:" + strings.TrimPrefix(pattern[1:], "^") + ")")
+	if err != nil {
+		return nil, fmt.Errorf("invalid path regexp %q: %v", pattern, err)
+	}
+	p := &PathPattern{pattern: pattern, re: re, regex: true, params: make(map[string]string)}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			p.params[name] = name
+		}
+	}
+	prefix, _ := re.LiteralPrefix()
+	p.literal = len(prefix)
+	return p, nil
+}
+
+// closingBrace返回与start处的{匹配的}的位置，参数的正则表达式中可以包含成对的花括号
+func closingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Match匹配请求路径，返回路径参数，*为剩余的路径（不包含开头的/）
+func (p *PathPattern) Match(path string) (map[string]string, bool) {
+	loc := p.re.FindStringSubmatchIndex(path)
+	if loc == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(p.params)+1)
+	for i, group := range p.re.SubexpNames() {
+		if group == "" || loc[2*i] < 0 {
+			continue
+		}
+		value := path[loc[2*i]:loc[2*i+1]]
+		if !p.regex && group == "rest" {
+			params["*"] = strings.TrimPrefix(value, "/")
+		} else if name, ok := p.params[group]; ok {
+			params[name] = value
+		}
+	}
+	if p.regex {
+		// 正则表达式需要在路径段的边界结束
+		rest := path[loc[1]:]
+		if rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasSuffix(path[:loc[1]], "/") {
+			return nil, false
+		}
+		params["*"] = strings.TrimPrefix(rest, "/")
+	}
+	if _, ok := params["*"]; !ok {
+		params["*"] = ""
+	}
+	return params, true
+}
+
+// Params返回模式中的参数名称
+func (p *PathPattern) Params() []string {
+	names := make([]string, 0, len(p.params))
+	for _, name := range p.params {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Specificity返回模式的具体程度，多个路由匹配同一路径时选择更具体的路由
+func (p *PathPattern) Specificity() int {
+	return p.literal
+}
@@ -0,0 +1,83 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompilePathErrors(t *testing.T) {
+	for _, pattern := range []string{
+		"",
+		"users",
+		"/users/{id",
+		"/users/}",
+		"/files/*/more/*",
+		"/users/{1id}",
+		"/users/{id}/{id}",
+		"/users/{id:(}",
+		"~(",
+	} {
+		if _, err := CompilePath(pattern); err == nil {
+			t.Errorf("CompilePath(%q) should fail", pattern)
+		}
+	}
+}
+
+func TestPathMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		params  map[string]string // 为nil表示不匹配
+	}{
+		{"/users", "/users", map[string]string{"*": ""}},
+		{"/users", "/users/1/orders", map[string]string{"*": "1/orders"}},
+		{"/users", "/usersx", nil},
+		{"/users/", "/users/1", map[string]string{"*": "1"}},
+		{"/users/", "/users", nil},
+		{"/users/{id}", "/users/42", map[string]string{"id": "42", "*": ""}},
+		{"/users/{id}", "/users/42/orders", map[string]string{"id": "42", "*": "orders"}},
+		{"/users/{id}", "/users/", nil},
+		{"/users/{id:[0-9]+}", "/users/abc", nil},
+		{"/codes/{code:[0-9]{3}}", "/codes/404", map[string]string{"code": "404", "*": ""}},
+		{"/codes/{code:[0-9]{3}}", "/codes/4044", nil},
+		{"/files/*", "/files/a/b.txt", map[string]string{"*": "a/b.txt"}},
+		{"/files/*.txt", "/files/a/b.txt", map[string]string{"*": "a/b"}},
+		{"/files/*.txt", "/files/a/b.png", nil},
+		{"/a.b", "/axb", nil},
+		{"~^/v(?P<version>[0-9]+)", "/v2/users", map[string]string{"version": "2", "*": "users"}},
+		{"~^/v(?P<version>[0-9]+)", "/v2", map[string]string{"version": "2", "*": ""}},
+		{"~^/v(?P<version>[0-9]+)", "/v2x/users", nil},
+		{"~/api/", "/api/users", map[string]string{"*": "users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			p, err := CompilePath(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			params, ok := p.Match(tt.path)
+			if ok != (tt.params != nil) {
+				t.Fatalf("Match(%q) ok = %v, params %v", tt.path, ok, params)
+			}
+			if ok && !reflect.DeepEqual(params, tt.params) {
+				t.Fatalf("Match(%q) = %v, want %v", tt.path, params, tt.params)
+			}
+		})
+	}
+}
+
+func TestPathSpecificity(t *testing.T) {
+	specificity := func(pattern string) int {
+		p, err := CompilePath(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.Specificity()
+	}
+	if specificity("/users/me") <= specificity("/users/{id}") {
+		t.Error("literal segment should be more specific than a parameter")
+	}
+	if specificity("/users/{id}/orders") <= specificity("/users") {
+		t.Error("longer pattern should be more specific")
+	}
+}
@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// QueryRule 查询参数的转换规则，操作与请求头规则相同
+type QueryRule struct {
+	Action string
+	Name   string
+	Value  string // add和set的值，支持模板变量
+	To     string // rename的目标名称
+}
+
+// QueryRules 编译后的查询参数转换规则，按顺序执行
+type QueryRules []compiledQueryRule
+
+type compiledQueryRule struct {
+	action string
+	name   string
+	to     string
+	value  *Template
+}
+
+// CompileQueryRules校验并编译查询参数转换规则
+func CompileQueryRules(rules []QueryRule) (QueryRules, error) {
+	compiled := make(QueryRules, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: empty query parameter name", i)
+		}
+		c := compiledQueryRule{action: strings.ToLower(rule.Action), name: rule.Name}
+		switch c.action {
+		case ActionAdd, ActionSet:
+			value, err := ParseTemplate(rule.Value, RequestVariable)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+			c.value = value
+		case ActionRemove:
+		case ActionRename:
+			if rule.To == "" {
+				return nil, fmt.Errorf("rule %d: empty target query parameter name", i)
+			}
+			c.to = rule.To
+		default:
+			return nil, fmt.Errorf("rule %d: unknown action %q, expected add, set, remove or rename", i, rule.Action)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// Apply按顺序对查询参数执行转换
+func (rules QueryRules) Apply(query url.Values, vars Vars) {
+	for _, rule := range rules {
+		switch rule.action {
+		case ActionAdd:
+			query.Add(rule.name, rule.value.Execute(vars))
+		case ActionSet:
+			query.Set(rule.name, rule.value.Execute(vars))
+		case ActionRemove:
+			query.Del(rule.name)
+		case ActionRename:
+			if values, ok := query[rule.name]; ok {
+				query.Del(rule.name)
+				query[rule.to] = values
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsafePath 改写后的路径包含.或..路径段
+var ErrUnsafePath = errors.New("rewritten path contains dot segments")
+
+// Rewrite 转发到下游前的路径和查询参数改写
+type Rewrite struct {
+	path        *Template
+	stripPrefix string
+	addPrefix   string
+	query       QueryRules
+}
+
+// RewriteOptions 改写配置
+type RewriteOptions struct {
+	Path        string   // 改写后的路径模板，{name}为路径参数，{*}为剩余路径，也可以使用请求变量
+	Params      []string // 路由路径模式中的参数名称
+	StripPrefix string   // 去掉的路径前缀，设置了Path时不生效
+	AddPrefix   string   // 添加的路径前缀
+	Query       []QueryRule
+}
+
+// CompileRewrite校验并编译改写配置
+func CompileRewrite(opts RewriteOptions) (*Rewrite, error) {
+	r := &Rewrite{
+		stripPrefix: strings.TrimSuffix(opts.StripPrefix, "/"),
+		addPrefix:   opts.AddPrefix,
+	}
+	if opts.Path != "" {
+		params := map[string]bool{"*": true}
+		for _, name := range opts.Params {
+			params[name] = true
+		}
+		path, err := ParseTemplate(opts.Path, func(name string) bool {
+			return params[name] || RequestVariable(name)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("rewrite path: %v", err)
+		}
+		r.path = path
+	}
+	for _, prefix := range []string{opts.StripPrefix, opts.AddPrefix} {
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("prefix %q must start with /", prefix)
+		}
+	}
+	query, err := CompileQueryRules(opts.Query)
+	if err != nil {
+		return nil, fmt.Errorf("query rules: %v", err)
+	}
+	r.query = query
+	return r, nil
+}
+
+// Apply改写请求地址的路径和查询参数
+func (r *Rewrite) Apply(u *url.URL, vars *RequestVars) error {
+	path := u.Path
+	switch {
+	case r.path != nil:
+		path = r.path.Execute(VarsFunc(func(name string) (string, bool) {
+			if value, ok := vars.PathParams[name]; ok {
+				return value, true
+			}
+			return vars.Lookup(name)
+		}))
+		if UnsafePath(path) {
+			return ErrUnsafePath
+		}
+	case r.stripPrefix != "":
+		if path == r.stripPrefix || strings.HasPrefix(path, r.stripPrefix+"/") {
+			path = strings.TrimPrefix(path, r.stripPrefix)
+		}
+	}
+	if r.addPrefix != "" {
+		if rest := strings.TrimPrefix(path, "/"); rest != "" {
+			path = strings.TrimSuffix(r.addPrefix, "/") + "/" + rest
+		} else {
+			path = r.addPrefix
+		}
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if path != u.Path {
+		u.Path = path
+		u.RawPath = ""
+	}
+
+	if len(r.query) > 0 {
+		query := u.Query()
+		r.query.Apply(query, vars)
+		u.RawQuery = query.Encode()
+	}
+	return nil
+}
+
+// UnsafePath判断路径是否包含.或..路径段，路径参数的值可能让改写后的路径跳出目标前缀
+func UnsafePath(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Vars 模板变量，变量不存在时返回false
+type Vars interface {
+	Lookup(name string) (string, bool)
+}
+
+// VarsFunc 把函数转换为Vars
+type VarsFunc func(name string) (string, bool)
+
+func (f VarsFunc) Lookup(name string) (string, bool) { return f(name) }
+
+// 请求变量中可以直接使用的名称
+var requestVariables = map[string]bool{
+	"client_ip":    true,
+	"request_id":   true,
+	"method":       true,
+	"host":         true,
+	"path":         true,
+	"route":        true,
+	"consumer":     true,
+	"time":         true,
+	"time.unix":    true,
+	"time.unix_ms": true,
+}
+
+// 请求变量中需要带名称的前缀，如header.X-User、query.page、path.id、jwt.sub
+var requestVariablePrefixes = []string{"header.", "query.", "path.", "jwt."}
+
+// RequestVariable判断是否为网关提供的请求变量
+func RequestVariable(name string) bool {
+	if requestVariables[name] {
+		return true
+	}
+	for _, prefix := range requestVariablePrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Template 编译后的模板，{name}会被替换为变量的值，{{和}}表示字面的花括号
+type Template struct {
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal  string
+	variable string // 不为空时为变量
+}
+
+// ParseTemplate解析模板，known不为nil时校验变量名称
+func ParseTemplate(s string, known func(name string) bool) (*Template, error) {
+	t := &Template{}
+	var literal strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "}}"):
+			literal.WriteByte(s[i])
+			i++
+		case s[i] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed variable in template %q", s)
+			}
+			name := strings.TrimSpace(s[i+1 : i+end])
+			if name == "" {
+				return nil, fmt.Errorf("empty variable in template %q", s)
+			}
+			if known != nil && !known(name) {
+				return nil, fmt.Errorf("unknown variable {%s} in template %q", name, s)
+			}
+			if literal.Len() > 0 {
+				t.parts = append(t.parts, templatePart{literal: literal.String()})
+				literal.Reset()
+			}
+			t.parts = append(t.parts, templatePart{variable: name})
+			i += end
+		case s[i] == '}':
+			return nil, fmt.Errorf("unexpected } in template %q", s)
+		default:
+			literal.WriteByte(s[i])
+		}
+	}
+	if literal.Len() > 0 {
+		t.parts = append(t.parts, templatePart{literal: literal.String()})
+	}
+	return t, nil
+}
+
+// Execute展开模板，不存在的变量替换为空字符串
+func (t *Template) Execute(vars Vars) string {
+	if len(t.parts) == 1 && t.parts[0].variable == "" {
+		return t.parts[0].literal
+	}
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.variable == "" {
+			b.WriteString(part.literal)
+			continue
+		}
+		if value, ok := vars.Lookup(part.variable); ok {
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
+
+// Variables返回模板中使用的变量
+func (t *Template) Variables() []string {
+	var names []string
+	for _, part := range t.parts {
+		if part.variable != "" {
+			names = append(names, part.variable)
+		}
+	}
+	return names
+}
@@ -0,0 +1,94 @@
+package transform
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestVars 请求相关的模板变量
+type RequestVars struct {
+	Request    *http.Request
+	ClientIP   string
+	RequestID  string
+	Route      string
+	Consumer   string
+	PathParams map[string]string // 路由路径参数，*为路由前缀之后的剩余路径
+	Now        time.Time
+	Claims     map[string]any // 网关已校验签名的JWT声明，路由未使用JWT认证时为空
+}
+
+// Lookup返回变量的值，请求头、查询参数、路径参数和JWT声明不存在时返回false
+func (v *RequestVars) Lookup(name string) (string, bool) {
+	switch name {
+	case "client_ip":
+		return v.ClientIP, true
+	case "request_id":
+		return v.RequestID, true
+	case "method":
+		return v.Request.Method, true
+	case "host":
+		return v.Request.Host, true
+	case "path":
+		return v.Request.URL.Path, true
+	case "route":
+		return v.Route, true
+	case "consumer":
+		return v.Consumer, true
+	case "time":
+		return v.Now.UTC().Format(time.RFC3339), true
+	case "time.unix":
+		return strconv.FormatInt(v.Now.Unix(), 10), true
+	case "time.unix_ms":
+		return strconv.FormatInt(v.Now.UnixMilli(), 10), true
+	}
+	prefix, key, _ := strings.Cut(name, ".")
+	switch prefix {
+	case "header":
+		values := v.Request.Header.Values(key)
+		if len(values) == 0 {
+			return "", false
+		}
+		return strings.Join(values, ","), true
+	case "query":
+		values, ok := v.Request.URL.Query()[key]
+		if !ok {
+			return "", false
+		}
+		return strings.Join(values, ","), true
+	case "path":
+		value, ok := v.PathParams[key]
+		return value, ok
+	case "jwt":
+		return v.claim(key)
+	}
+	return "", false
+}
+
+// claim返回已校验的JWT声明，嵌套的声明使用.分隔，如jwt.realm_access.roles
+func (v *RequestVars) claim(name string) (string, bool) {
+	var value any = v.Claims
+	for _, key := range strings.Split(name, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case nil:
+		return "", false
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
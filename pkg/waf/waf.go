@@ -0,0 +1,247 @@
+package waf
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 规则检查的请求部位
+const (
+	TargetPath    = "path"
+	TargetQuery   = "query"
+	TargetHeaders = "headers"
+	TargetBody    = "body"
+)
+
+// 规则分类
+const (
+	CategorySQLi          = "sqli"
+	CategoryXSS           = "xss"
+	CategoryPathTraversal = "path-traversal"
+	CategoryCommand       = "command-injection"
+)
+
+// 策略模式
+const (
+	ModeBlock  = "block"  // 拦截匹配的请求
+	ModeDetect = "detect" // 仅记录日志，不拦截
+)
+
+// 匹配结果中保存的值的最大长度
+const maxMatchValue = 128
+
+// Rule 检测规则，Pattern为Go正则表达式，在解码后的值上匹配
+type Rule struct {
+	ID          string
+	Category    string
+	Targets     []string // 为空时检查所有部位
+	Pattern     string
+	Description string
+
+	re      *regexp.Regexp
+	targets map[string]bool
+}
+
+// NewRule校验并编译规则
+func NewRule(id, category string, targets []string, pattern, description string) (*Rule, error) {
+	if id == "" {
+		return nil, fmt.Errorf("rule id is required")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern of rule %s: %v", id, err)
+	}
+	r := &Rule{
+		ID:          id,
+		Category:    category,
+		Targets:     targets,
+		Pattern:     pattern,
+		Description: description,
+		re:          re,
+	}
+	if len(targets) > 0 {
+		r.targets = make(map[string]bool)
+		for _, t := range targets {
+			switch t {
+			case TargetPath, TargetQuery, TargetHeaders, TargetBody:
+				r.targets[t] = true
+			default:
+				return nil, fmt.Errorf("invalid target %q of rule %s", t, id)
+			}
+		}
+	}
+	return r, nil
+}
+
+func mustRule(id, category, pattern, description string, targets ...string) *Rule {
+	r, err := NewRule(id, category, targets, pattern, description)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// 内置规则，规则ID参考ModSecurity CRS的编号
+// 命令注入规则中的;、&、|在请求头中很常见（如Accept、User-Agent），与CRS一样只检查路径、查询参数和请求体
+var builtin = []*Rule{
+	mustRule("930100", CategoryPathTraversal, `(?:^|[/\\])\.\.(?:[/\\]|$)`, "Path traversal attack (../)"),
+	mustRule("930120", CategoryPathTraversal, `(?i)(?:/etc/(?:passwd|shadow|group|hosts)\b|/proc/self/|\\windows\\win\.ini|boot\.ini)`, "OS file access attempt"),
+	mustRule("932100", CategoryCommand, "(?i)(?:[;&|`]|\\$\\()\\s*(?:cat|ls|id|whoami|uname|wget|curl|nc|ncat|bash|sh|zsh|ping|nslookup|powershell|cmd)\\b", "Unix command injection", TargetPath, TargetQuery, TargetBody),
+	mustRule("932150", CategoryCommand, `(?i)(?:/bin/(?:ba|z)?sh\b|\bcmd(?:\.exe)?\s+/c\b|\bpowershell(?:\.exe)?\s+-)`, "Direct shell invocation"),
+	mustRule("941100", CategoryXSS, `(?i)<script[\s>/]`, "XSS script tag"),
+	mustRule("941110", CategoryXSS, `(?i)<[a-z][^>]*\son[a-z]+\s*=`, "XSS event handler attribute"),
+	mustRule("941120", CategoryXSS, `(?i)(?:javascript|vbscript)\s*:`, "XSS javascript URI"),
+	mustRule("941130", CategoryXSS, `(?i)<(?:iframe|object|embed|applet|base|meta)\b`, "XSS dangerous HTML tag"),
+	mustRule("942100", CategorySQLi, `(?i)\bunion\b(?:\s+all)?\s+(?:distinct\s+)?select\b`, "SQL injection UNION SELECT"),
+	mustRule("942110", CategorySQLi, `(?i)['"]\s*(?:or|and)\s+['"]?[\w]+['"]?\s*(?:=|<|>|like\b)`, "SQL injection tautology"),
+	mustRule("942120", CategorySQLi, `(?i)['"]\s*(?:--|#|/\*)`, "SQL injection comment termination"),
+	mustRule("942130", CategorySQLi, `(?i);\s*(?:drop|delete|insert|update|alter|create|truncate|exec(?:ute)?)\s`, "SQL injection stacked query"),
+	mustRule("942140", CategorySQLi, `(?i)\b(?:sleep|benchmark|pg_sleep)\s*\(|\bwaitfor\s+delay\b`, "SQL injection time-based probe"),
+	mustRule("942150", CategorySQLi, `(?i)\binformation_schema\b|\bsys\.(?:tables|objects)\b`, "SQL injection schema enumeration"),
+}
+
+// Builtin返回内置规则
+func Builtin() []*Rule {
+	return builtin
+}
+
+// IsBuiltin判断规则ID是否与内置规则冲突
+func IsBuiltin(id string) bool {
+	for _, r := range builtin {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Input 待检查的请求内容
+type Input struct {
+	Path    string
+	Query   string // 原始查询字符串
+	Headers http.Header
+	Body    string // 已按大小截断的请求体
+	Form    bool   // 请求体是否为表单编码
+}
+
+// Match 规则匹配结果
+type Match struct {
+	RuleID   string
+	Category string
+	Target   string
+	Name     string // 匹配的参数名或请求头名称
+	Value    string
+}
+
+// field 已解码的待检查字段
+type field struct {
+	target string
+	name   string
+	value  string
+}
+
+// Inspect使用规则检查请求，返回所有匹配结果，每条规则最多匹配一次
+func Inspect(rules []*Rule, in *Input) []Match {
+	fields := in.fields()
+	var matches []Match
+	for _, r := range rules {
+		for _, f := range fields {
+			if r.targets != nil && !r.targets[f.target] {
+				continue
+			}
+			if r.re.MatchString(f.value) {
+				value := f.value
+				if len(value) > maxMatchValue {
+					value = value[:maxMatchValue]
+				}
+				matches = append(matches, Match{
+					RuleID:   r.ID,
+					Category: r.Category,
+					Target:   f.target,
+					Name:     f.name,
+					Value:    value,
+				})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// fields将请求拆分为解码后的字段，参数名和参数值都会检查
+func (in *Input) fields() []field {
+	fields := []field{{target: TargetPath, value: decode(in.Path)}}
+	fields = appendValues(fields, TargetQuery, in.Query)
+
+	names := make([]string, 0, len(in.Headers))
+	for name := range in.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range in.Headers[name] {
+			if name == "Cookie" {
+				fields = appendCookies(fields, v)
+				continue
+			}
+			fields = append(fields, field{target: TargetHeaders, name: name, value: decode(v)})
+		}
+	}
+
+	if in.Body != "" {
+		if in.Form {
+			fields = appendValues(fields, TargetBody, in.Body)
+		} else {
+			fields = append(fields, field{target: TargetBody, value: in.Body})
+		}
+	}
+	return fields
+}
+
+// appendValues拆分URL编码的参数
+func appendValues(fields []field, target, raw string) []field {
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		name = decode(name)
+		fields = append(fields,
+			field{target: target, name: name, value: name},
+			field{target: target, name: name, value: decode(value)})
+	}
+	return fields
+}
+
+// appendCookies拆分Cookie请求头，分别检查每个Cookie的名称和值，分隔符;不会参与匹配
+func appendCookies(fields []field, raw string) []field {
+	for _, pair := range strings.Split(raw, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if name == "" {
+			continue
+		}
+		fields = append(fields,
+			field{target: TargetHeaders, name: "Cookie:" + name, value: decode(name)},
+			field{target: TargetHeaders, name: "Cookie:" + name, value: decode(value)})
+	}
+	return fields
+}
+
+// decode对值进行多次URL解码，防止通过多重编码绕过检测
+func decode(s string) string {
+	for i := 0; i < 3; i++ {
+		if !strings.ContainsAny(s, "%+") {
+			break
+		}
+		decoded, err := url.QueryUnescape(s)
+		if err != nil || decoded == s {
+			break
+		}
+		s = decoded
+	}
+	return strings.ReplaceAll(s, "\x00", "")
+}
@@ -0,0 +1,42 @@
+package waf
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestInspectBuiltin(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Input
+		rule string // 期望匹配的规则，为空表示不应匹配
+	}{
+		{"plain request", Input{Path: "/users/1", Query: "page=2&sort=name"}, ""},
+		{"cookie separators", Input{Path: "/", Headers: http.Header{"Cookie": {"session=x; id=5; lang=sh"}}}, ""},
+		{"accept header", Input{Path: "/", Headers: http.Header{"Accept": {"text/html;q=0.9, */*;q=0.8"}}}, ""},
+		{"user agent", Input{Path: "/", Headers: http.Header{"User-Agent": {"Mozilla/5.0 (X11; Linux x86_64) curl/8.0"}}}, ""},
+		{"command in query", Input{Path: "/", Query: "host=example.com;cat%20/etc/hosts"}, "932100"},
+		{"command in body", Input{Path: "/", Body: "host=a%7Cwhoami", Form: true}, "932100"},
+		{"command in path", Input{Path: "/ping/a;id"}, "932100"},
+		{"sqli in cookie value", Input{Path: "/", Headers: http.Header{"Cookie": {"id=1; name=%27%20or%20%271%27%3D%271"}}}, "942110"},
+		{"xss in header", Input{Path: "/", Headers: http.Header{"Referer": {"<script>alert(1)</script>"}}}, "941100"},
+		{"double encoded traversal", Input{Path: "/files/%252e%252e/secret"}, "930100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := Inspect(Builtin(), &tt.in)
+			if tt.rule == "" {
+				if len(matches) > 0 {
+					t.Fatalf("Inspect() = %+v, want no match", matches)
+				}
+				return
+			}
+			for _, m := range matches {
+				if m.RuleID == tt.rule {
+					return
+				}
+			}
+			t.Fatalf("Inspect() = %+v, want rule %s", matches, tt.rule)
+		})
+	}
+}
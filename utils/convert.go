@@ -11,3 +11,15 @@ func Itob(v int) []byte {
 func Btoi(b []byte) int {
 	return int(binary.BigEndian.Uint64(b))
 }
+
+// PrefixUpperBound返回前缀扫描的上界（不包含），用于pebble迭代器的UpperBound
+func PrefixUpperBound(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}